@@ -0,0 +1,287 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// StaleBranch is a local branch that is probably no longer needed: its
+// upstream was deleted on the remote (Gone), or all its commits are in the
+// default branch (Merged). LastCommit is the commit time in Unix seconds.
+type StaleBranch struct {
+	Name       string `json:"name"`
+	Upstream   string `json:"upstream"`
+	Gone       bool   `json:"gone"`
+	Merged     bool   `json:"merged"`
+	Hash       string `json:"hash"`
+	Subject    string `json:"subject"`
+	LastCommit int64  `json:"lastCommit"`
+}
+
+// StaleBranchReport lists the stale branches of a repository and the default
+// branch they were compared with, which is empty if none could be found.
+type StaleBranchReport struct {
+	DefaultBranch string        `json:"defaultBranch"`
+	Branches      []StaleBranch `json:"branches"`
+}
+
+// GetStaleBranches lists the local branches whose upstream is gone or that
+// are fully merged into the default branch, oldest first. The current branch
+// and the default branch itself are never listed.
+func (a *App) GetStaleBranches(repoPath string) (*StaleBranchReport, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return nil, err
+	}
+	tracking, err := branchTracking(repoPath, r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StaleBranchReport{Branches: []StaleBranch{}}
+	var defaultTips []plumbing.Hash
+	report.DefaultBranch, defaultTips = defaultBranch(repoPath, r)
+	current := currentBranch(r)
+
+	for _, t := range tracking {
+		if t.Branch == current || t.Branch == report.DefaultBranch {
+			continue
+		}
+		ref, err := r.Reference(plumbing.NewBranchReferenceName(t.Branch), true)
+		if err != nil {
+			continue
+		}
+		b := StaleBranch{
+			Name:     t.Branch,
+			Upstream: t.Upstream,
+			Gone:     t.Gone,
+			Merged:   mergedInto(r, idx, ref.Hash(), defaultTips),
+			Hash:     ref.Hash().String(),
+		}
+		if !b.Gone && !b.Merged {
+			continue
+		}
+		if c, err := r.CommitObject(ref.Hash()); err == nil {
+			b.Subject, _, _ = strings.Cut(c.Message, "\n")
+			b.LastCommit = c.Committer.When.Unix()
+		}
+		report.Branches = append(report.Branches, b)
+	}
+
+	sort.SliceStable(report.Branches, func(i, j int) bool {
+		return report.Branches[i].LastCommit < report.Branches[j].LastCommit
+	})
+	return report, nil
+}
+
+// DeleteBranches deletes several local branches at once. Without force, a
+// branch is only deleted when it is merged into HEAD, its upstream or the
+// default branch, like git branch -d. Branches that cannot be deleted do not
+// stop the others; the failures are returned together.
+func (a *App) DeleteBranches(repoPath string, names []string, force bool) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return err
+	}
+
+	_, defaultTips := defaultBranch(repoPath, r)
+	if head, err := r.Head(); err == nil {
+		defaultTips = append(defaultTips, head.Hash())
+	}
+	current := currentBranch(r)
+
+	var errs []error
+	deleted := 0
+	for _, name := range names {
+		ref, err := r.Reference(plumbing.NewBranchReferenceName(name), true)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("branch %s: %w", name, err))
+			continue
+		}
+		if name == current {
+			errs = append(errs, fmt.Errorf("cannot delete the checked out branch %s", name))
+			continue
+		}
+		if !force {
+			tips := defaultTips
+			if b, ok := cfg.Branches[name]; ok && b.Remote != "" && b.Merge != "" {
+				if upstream, err := r.Reference(trackingRef(cfg, b.Remote, b.Merge), true); err == nil {
+					tips = append(append([]plumbing.Hash{}, tips...), upstream.Hash())
+				}
+			}
+			if !mergedInto(r, idx, ref.Hash(), tips) {
+				errs = append(errs, fmt.Errorf("the branch %s is not fully merged", name))
+				continue
+			}
+		}
+
+		if err := r.Storer.RemoveReference(ref.Name()); err != nil {
+			errs = append(errs, fmt.Errorf("branch %s: %w", name, err))
+			continue
+		}
+		delete(cfg.Branches, name)
+		deleted++
+	}
+
+	if deleted > 0 {
+		if err := r.SetConfig(cfg); err != nil {
+			errs = append(errs, err)
+		}
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Deleted %d branch(es)", deleted),
+			Percent: -1,
+		})
+	}
+	return errors.Join(errs...)
+}
+
+// defaultBranch returns the name of the default branch and the commits of its
+// local and remote-tracking refs. The default branch is the HEAD of the
+// default remote, else the first of init.defaultBranch, main and master that
+// exists.
+func defaultBranch(repoPath string, r *git.Repository) (string, []plumbing.Hash) {
+	remote, _ := defaultRemote(repoPath, r, false)
+
+	name := ""
+	if remote != "" {
+		head, err := r.Storer.Reference(plumbing.NewRemoteHEADReferenceName(remote))
+		if err == nil && head.Type() == plumbing.SymbolicReference {
+			name = strings.TrimPrefix(head.Target().String(), "refs/remotes/"+remote+"/")
+		}
+	}
+	if name == "" {
+		candidates := []string{"main", "master"}
+		if gc, err := loadGitConfig(repoPath); err == nil {
+			if v, ok := gc.Get("init.defaultBranch"); ok && v != "" {
+				candidates = append([]string{v}, candidates...)
+			}
+		}
+		for _, c := range candidates {
+			if _, err := r.Reference(plumbing.NewBranchReferenceName(c), false); err == nil {
+				name = c
+				break
+			}
+			if remote != "" {
+				if _, err := r.Reference(plumbing.NewRemoteReferenceName(remote, c), false); err == nil {
+					name = c
+					break
+				}
+			}
+		}
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	refs := []plumbing.ReferenceName{plumbing.NewBranchReferenceName(name)}
+	if remote != "" {
+		refs = append(refs, plumbing.NewRemoteReferenceName(remote, name))
+	}
+	var tips []plumbing.Hash
+	for _, refName := range refs {
+		if ref, err := r.Reference(refName, true); err == nil {
+			tips = append(tips, ref.Hash())
+		}
+	}
+	return name, tips
+}
+
+// mergedInto reports whether commit is reachable from any of the tips.
+func mergedInto(r *git.Repository, idx *historyIndex, commit plumbing.Hash, tips []plumbing.Hash) bool {
+	for _, tip := range tips {
+		if tip == commit || isFastForward(r, idx, commit, tip) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneConfigured reports whether fetches from a remote prune by default:
+// remote.<name>.prune, else fetch.prune.
+func pruneConfigured(repoPath string, remote string) bool {
+	gc, err := loadGitConfig(repoPath)
+	if err != nil {
+		return false
+	}
+	if v, ok := gc.Get("remote." + remote + ".prune"); ok {
+		return parseConfigBool(v)
+	}
+	return gc.GetBool("fetch.prune", false)
+}
+
+// pruneRemote removes the remote-tracking refs of a remote whose source ref
+// no longer exists on the remote, like git fetch --prune. Only refs that the
+// remote's fetch refspecs map to are considered.
+func (a *App) pruneRemote(ctx context.Context, r *git.Repository, repoPath string, remote *git.Remote) error {
+	remoteRefs, err := a.lsRemote(ctx, repoPath, remote.Config().URLs[0])
+	if err != nil {
+		return err
+	}
+
+	refs, err := r.References()
+	if err != nil {
+		return err
+	}
+	var stale []plumbing.ReferenceName
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		for _, spec := range remote.Config().Fetch {
+			// RefSpec.Reverse does not handle the force prefix
+			reverse := config.RefSpec(strings.TrimPrefix(spec.String(), "+")).Reverse()
+			if !reverse.Match(ref.Name()) {
+				continue
+			}
+			if _, ok := remoteRefs[reverse.Dst(ref.Name())]; !ok {
+				stale = append(stale, ref.Name())
+			}
+			break
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range stale {
+		if err := r.Storer.RemoveReference(name); err != nil {
+			return err
+		}
+	}
+	if len(stale) > 0 {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Pruned %d stale branch(es) of %s", len(stale), remote.Config().Name),
+			Percent: -1,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// CloneOptions describes a clone. URL is an SSH, HTTPS or local URL (a path
+// or file://). Directory is the path of the new repository; it must not exist
+// or be empty.
+//
+// Branch checks out that branch instead of the remote's default branch, and
+// SingleBranch fetches only that branch. Depth > 0 makes a shallow clone with
+// that many commits. RecurseSubmodules clones all submodules recursively.
+// Bare clones have no worktree; Mirror is a bare clone that copies all refs
+// and keeps them in sync on fetch.
+type CloneOptions struct {
+	URL               string `json:"url"`
+	Directory         string `json:"directory"`
+	Branch            string `json:"branch"`
+	Depth             int    `json:"depth"`
+	SingleBranch      bool   `json:"singleBranch"`
+	RecurseSubmodules bool   `json:"recurseSubmodules"`
+	Bare              bool   `json:"bare"`
+	Mirror            bool   `json:"mirror"`
+}
+
+// Clone clones a repository into opts.Directory and returns its absolute
+// path. A failed or cancelled clone removes what it created.
+func (a *App) Clone(opts CloneOptions) (_ string, err error) {
+	url := strings.TrimSpace(opts.URL)
+	if url == "" {
+		return "", errors.New("the repository URL is empty")
+	}
+	if opts.Directory == "" {
+		return "", errors.New("the target directory is empty")
+	}
+	if opts.Depth < 0 {
+		return "", fmt.Errorf("invalid depth %d", opts.Depth)
+	}
+	dir, err := filepath.Abs(opts.Directory)
+	if err != nil {
+		return "", err
+	}
+
+	op := a.startOperation(OperationClone, dir)
+	defer func() { err = a.finishOperation(op, err) }()
+
+	mu := getRepoMutex(dir)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// Like git, refuse to clone into a directory that has files in it
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return "", fmt.Errorf("the directory %s already exists and is not empty", dir)
+	} else if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	auth, err := a.getAuth(op.ctx, dir, url)
+	if err != nil {
+		return "", err
+	}
+
+	status := fmt.Sprintf("Cloning %s...", url)
+	progress := &gitProgressProxy{a: a, status: status}
+	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+		Status:  status,
+		Percent: 0,
+	})
+
+	cloneOpts := &git.CloneOptions{
+		URL:          url,
+		SingleBranch: opts.SingleBranch,
+		Mirror:       opts.Mirror,
+		Depth:        opts.Depth,
+		Progress:     progress,
+	}
+	if opts.Branch != "" {
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(opts.Branch)
+	}
+	if opts.RecurseSubmodules {
+		cloneOpts.RecurseSubmodules = git.DefaultSubmoduleRecursionDepth
+	}
+
+	var r *git.Repository
+	err = a.withAuth(op.ctx, dir, url, auth, func(auth transport.AuthMethod) error {
+		cloneOpts.Auth = auth
+		r, err = git.PlainCloneContext(op.ctx, dir, opts.Bare, cloneOpts)
+		return err
+	})
+	if err == nil && opts.Bare && !opts.Mirror {
+		err = finishBareClone(r)
+	}
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		// Like git, cloning an empty repository gives an empty repository
+		// with the remote configured.
+		err = initEmptyClone(dir, url, opts.Bare, opts.Mirror)
+	}
+	if err != nil {
+		if op.ctx.Err() != nil {
+			return "", err
+		}
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Clone failed: %v", err),
+			Percent: -1,
+		})
+		return "", err
+	}
+
+	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+		Status:  "Clone completed",
+		Percent: 100,
+	})
+	return dir, nil
+}
+
+// DefaultCloneDirectory returns the directory name git would clone a URL
+// into: the last path component without ".git", or with ".git" for bare
+// clones.
+func (a *App) DefaultCloneDirectory(url string, bare bool) string {
+	name := strings.TrimRight(strings.TrimSpace(url), "/\\")
+	name = strings.TrimSuffix(name, "/.git")
+	if i := strings.LastIndexAny(name, "/\\:"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, ".git")
+	if name == "" {
+		return ""
+	}
+	if bare {
+		name += ".git"
+	}
+	return name
+}
+
+// finishBareClone makes a bare clone look like git clone --bare: the remote
+// branches become local branches, without upstreams.
+func finishBareClone(r *git.Repository) error {
+	refs, err := r.References()
+	if err != nil {
+		return err
+	}
+	prefix := "refs/remotes/" + git.DefaultRemoteName + "/"
+	var tracking []*plumbing.Reference
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), prefix) {
+			tracking = append(tracking, ref)
+		}
+		return nil
+	})
+	for _, ref := range tracking {
+		if ref.Type() == plumbing.HashReference {
+			branch := plumbing.NewBranchReferenceName(strings.TrimPrefix(ref.Name().String(), prefix))
+			if err := r.Storer.SetReference(plumbing.NewHashReference(branch, ref.Hash())); err != nil {
+				return err
+			}
+		}
+		if err := r.Storer.RemoveReference(ref.Name()); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+	cfg.Branches = map[string]*config.Branch{}
+	return r.SetConfig(cfg)
+}
+
+// initEmptyClone sets up the repository that cloning an empty remote leaves
+// behind. go-git removes the directory when the remote is empty.
+func initEmptyClone(dir string, url string, bare bool, mirror bool) error {
+	r, err := git.PlainInit(dir, bare || mirror)
+	if err != nil {
+		return err
+	}
+	rc := &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}}
+	if mirror {
+		rc.Mirror = true
+		rc.Fetch = []config.RefSpec{"+refs/*:refs/*"}
+	}
+	_, err = r.CreateRemote(rc)
+	return err
+}
@@ -0,0 +1,337 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// CommitMessageTemplate is a commit message template stored by the app for a
+// repository. The first line of Content is the subject.
+type CommitMessageTemplate struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// CommitMessageRules configures the commit message validator. Zero values
+// disable a rule.
+type CommitMessageRules struct {
+	// ConventionalCommits requires "type(scope)!: description" subjects.
+	ConventionalCommits bool `json:"conventionalCommits"`
+	// ConventionalTypes restricts the allowed types; empty allows the
+	// Angular convention types.
+	ConventionalTypes []string `json:"conventionalTypes"`
+	MaxSubjectLength  int      `json:"maxSubjectLength"`
+	// TicketPattern is a regular expression, e.g. "[A-Z]+-[0-9]+", that must
+	// match the subject or body.
+	TicketPattern string `json:"ticketPattern"`
+	// BodyLineWrap is the maximum length of body lines. Lines without
+	// spaces, such as URLs, cannot be wrapped and are not checked.
+	BodyLineWrap int `json:"bodyLineWrap"`
+}
+
+// CommitMessageSettings are the templates and validation rules of a
+// repository.
+type CommitMessageSettings struct {
+	Templates []CommitMessageTemplate `json:"templates"`
+	Rules     CommitMessageRules      `json:"rules"`
+}
+
+// CommitMessageViolation is a rule a commit message does not satisfy. Line
+// is the 1-based line of the message, 0 for the message as a whole.
+type CommitMessageViolation struct {
+	Rule    string `json:"rule"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// CommitMessageError is returned when a commit is rejected by the commit
+// message rules.
+type CommitMessageError struct {
+	Violations []CommitMessageViolation
+}
+
+func (e *CommitMessageError) Error() string {
+	lines := []string{"the commit message does not follow the repository's rules:"}
+	for _, v := range e.Violations {
+		lines = append(lines, "- "+v.Message)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var defaultConventionalTypes = []string{
+	"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test",
+}
+
+var conventionalSubjectRegex = regexp.MustCompile(`^([a-zA-Z]+)(\([^()\r\n]+\))?(!)?: (\S.*)$`)
+
+var commitMessagesMu sync.Mutex
+
+// GetCommitTemplate returns the contents of the commit.template file, or ""
+// if none is configured. Relative paths are resolved against the worktree.
+func (a *App) GetCommitTemplate(repoPath string) (string, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	cfg, err := loadGitConfig(repoPath)
+	if err != nil {
+		return "", err
+	}
+	path, ok := cfg.Get("commit.template")
+	if !ok || path == "" {
+		return "", nil
+	}
+	path = expandHome(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoPath, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read commit.template %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+// GetCommitMessageSettings returns the templates and rules stored for a
+// repository.
+func (a *App) GetCommitMessageSettings(repoPath string) (*CommitMessageSettings, error) {
+	commitMessagesMu.Lock()
+	defer commitMessagesMu.Unlock()
+	return repoCommitMessageSettings(repoPath)
+}
+
+// SaveCommitMessageSettings replaces the templates and rules of a
+// repository. Templates without an ID are assigned one.
+func (a *App) SaveCommitMessageSettings(repoPath string, settings CommitMessageSettings) (*CommitMessageSettings, error) {
+	commitMessagesMu.Lock()
+	defer commitMessagesMu.Unlock()
+
+	for i := range settings.Templates {
+		t := &settings.Templates[i]
+		if t.ID == "" {
+			id, err := newProfileID()
+			if err != nil {
+				return nil, err
+			}
+			t.ID = id
+		}
+		if strings.TrimSpace(t.Name) == "" {
+			return nil, errors.New("every template needs a name")
+		}
+	}
+	if settings.Templates == nil {
+		settings.Templates = []CommitMessageTemplate{}
+	}
+
+	rules := &settings.Rules
+	if rules.MaxSubjectLength < 0 || rules.BodyLineWrap < 0 {
+		return nil, errors.New("lengths cannot be negative")
+	}
+	if rules.TicketPattern != "" {
+		if _, err := regexp.Compile(rules.TicketPattern); err != nil {
+			return nil, fmt.Errorf("invalid ticket pattern: %w", err)
+		}
+	}
+	var types []string
+	for _, t := range rules.ConventionalTypes {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	rules.ConventionalTypes = types
+	if rules.ConventionalTypes == nil {
+		rules.ConventionalTypes = []string{}
+	}
+
+	all, err := readCommitMessageSettings()
+	if err != nil {
+		return nil, err
+	}
+	all[repoSettingsKey(repoPath)] = settings
+
+	p, err := commitMessagesPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(p, data, 0600); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// ValidateCommitMessage checks a message against the repository's rules, so
+// violations can be shown before committing.
+func (a *App) ValidateCommitMessage(repoPath string, message string) ([]CommitMessageViolation, error) {
+	commitMessagesMu.Lock()
+	settings, err := repoCommitMessageSettings(repoPath)
+	commitMessagesMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return validateCommitMessage(settings.Rules, message), nil
+}
+
+// validateCommitMessage returns the rules a message violates. Comment lines
+// are ignored. Merge, revert and autosquash commits keep the subject git
+// generates for them, so only the length rules apply to them.
+func validateCommitMessage(rules CommitMessageRules, message string) []CommitMessageViolation {
+	violations := []CommitMessageViolation{}
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+
+	subjectLine := 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+			subjectLine = i + 1
+			break
+		}
+	}
+	if subjectLine == 0 {
+		return append(violations, CommitMessageViolation{Rule: "empty", Message: "the commit message is empty"})
+	}
+	subject := strings.TrimSpace(lines[subjectLine-1])
+	generated := false
+	for _, prefix := range []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, prefix) {
+			generated = true
+		}
+	}
+
+	if rules.MaxSubjectLength > 0 && utf8.RuneCountInString(subject) > rules.MaxSubjectLength {
+		violations = append(violations, CommitMessageViolation{
+			Rule:    "subject-length",
+			Line:    subjectLine,
+			Message: fmt.Sprintf("the subject is %d characters long, the maximum is %d", utf8.RuneCountInString(subject), rules.MaxSubjectLength),
+		})
+	}
+
+	if rules.ConventionalCommits && !generated {
+		types := rules.ConventionalTypes
+		if len(types) == 0 {
+			types = defaultConventionalTypes
+		}
+		if m := conventionalSubjectRegex.FindStringSubmatch(subject); m == nil {
+			violations = append(violations, CommitMessageViolation{
+				Rule:    "conventional-commits",
+				Line:    subjectLine,
+				Message: "the subject must have the form \"type(scope): description\"",
+			})
+		} else if !containsString(types, m[1]) {
+			violations = append(violations, CommitMessageViolation{
+				Rule:    "conventional-commits",
+				Line:    subjectLine,
+				Message: fmt.Sprintf("unknown commit type %q, use one of: %s", m[1], strings.Join(types, ", ")),
+			})
+		}
+	}
+
+	if rules.TicketPattern != "" && !generated {
+		re, err := regexp.Compile(rules.TicketPattern)
+		if err != nil {
+			violations = append(violations, CommitMessageViolation{
+				Rule:    "ticket",
+				Message: fmt.Sprintf("invalid ticket pattern: %v", err),
+			})
+		} else if !re.MatchString(stripCommentLines(message)) {
+			violations = append(violations, CommitMessageViolation{
+				Rule:    "ticket",
+				Message: fmt.Sprintf("the message must reference a ticket matching %s", rules.TicketPattern),
+			})
+		}
+	}
+
+	if rules.BodyLineWrap > 0 {
+		for i := subjectLine; i < len(lines); i++ {
+			line := strings.TrimRight(lines[i], " \t")
+			if strings.HasPrefix(line, "#") || !strings.ContainsAny(strings.TrimSpace(line), " \t") {
+				continue
+			}
+			if n := utf8.RuneCountInString(line); n > rules.BodyLineWrap {
+				violations = append(violations, CommitMessageViolation{
+					Rule:    "body-line-length",
+					Line:    i + 1,
+					Message: fmt.Sprintf("line %d is %d characters long, wrap the body at %d", i+1, n, rules.BodyLineWrap),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+func stripCommentLines(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func repoCommitMessageSettings(repoPath string) (*CommitMessageSettings, error) {
+	all, err := readCommitMessageSettings()
+	if err != nil {
+		return nil, err
+	}
+	settings, ok := all[repoSettingsKey(repoPath)]
+	if !ok {
+		settings = CommitMessageSettings{}
+	}
+	if settings.Templates == nil {
+		settings.Templates = []CommitMessageTemplate{}
+	}
+	if settings.Rules.ConventionalTypes == nil {
+		settings.Rules.ConventionalTypes = []string{}
+	}
+	return &settings, nil
+}
+
+func readCommitMessageSettings() (map[string]CommitMessageSettings, error) {
+	all := make(map[string]CommitMessageSettings)
+	p, err := commitMessagesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return all, nil
+}
+
+func commitMessagesPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "celerix-git", "commit-messages.json"), nil
+}
@@ -0,0 +1,179 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Kinds of fixup commits, named after git commit --fixup and --squash.
+const (
+	FixupKindFixup  = "fixup"
+	FixupKindSquash = "squash"
+	FixupKindAmend  = "amend"
+)
+
+// fixupMessage builds the message of a fixup, squash or amend commit for the
+// target, like git commit --fixup/--squash. msg is the message entered by the
+// user: the body of the commit, or for amend the new message of the target.
+func fixupMessage(r *git.Repository, target string, kind string, msg string) (string, error) {
+	hash, err := r.ResolveRevision(plumbing.Revision(target))
+	if err != nil {
+		return "", fmt.Errorf("cannot find fixup target %s: %w", target, err)
+	}
+	c, err := r.CommitObject(*hash)
+	if err != nil {
+		return "", err
+	}
+
+	subject := strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)[0]
+	// Like git, fixups of fixups target the original commit's subject
+	for _, prefix := range []string{"amend! ", "fixup! ", "squash! "} {
+		if rest, ok := strings.CutPrefix(subject, prefix); ok && kind != FixupKindAmend {
+			subject = rest
+			break
+		}
+	}
+	msg = strings.TrimSpace(msg)
+
+	switch kind {
+	case FixupKindFixup, "":
+		if msg == "" {
+			return "fixup! " + subject, nil
+		}
+		return "fixup! " + subject + "\n\n" + msg, nil
+	case FixupKindSquash:
+		if msg == "" {
+			return "squash! " + subject, nil
+		}
+		return "squash! " + subject + "\n\n" + msg, nil
+	case FixupKindAmend:
+		if msg == "" {
+			msg = strings.TrimSpace(c.Message)
+		}
+		return "amend! " + subject + "\n\n" + msg, nil
+	}
+	return "", fmt.Errorf("unknown fixup kind: %s", kind)
+}
+
+// authorDateLayouts are the accepted formats of CommitOptions.AuthorDate.
+var authorDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseAuthorDate parses an author date. Dates without a zone are local.
+func parseAuthorDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range authorDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid author date: %s", value)
+}
+
+// overrideAuthor returns the author with the non-empty overrides applied.
+func overrideAuthor(author *object.Signature, opts CommitOptions) (*object.Signature, error) {
+	result := *author
+	if opts.AuthorName != "" {
+		result.Name = strings.TrimSpace(opts.AuthorName)
+	}
+	if opts.AuthorEmail != "" {
+		if err := validateEmail(opts.AuthorEmail); err != nil {
+			return nil, err
+		}
+		result.Email = strings.TrimSpace(opts.AuthorEmail)
+	}
+	if opts.AuthorDate != "" {
+		when, err := parseAuthorDate(opts.AuthorDate)
+		if err != nil {
+			return nil, err
+		}
+		result.When = when
+	}
+	return &result, nil
+}
+
+// limitIndexToPaths returns a copy of the index in which the staged changes
+// outside of paths are reverted to HEAD, so committing it records only the
+// changes to paths. Paths are files or directories relative to the worktree.
+func limitIndexToPaths(r *git.Repository, idx *index.Index, paths []string) (*index.Index, error) {
+	var patterns []string
+	for _, p := range paths {
+		p = strings.Trim(path.Clean(strings.ReplaceAll(p, "\\", "/")), "/")
+		if p != "" && p != "." {
+			patterns = append(patterns, p)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New("no paths to commit")
+	}
+	matched := make([]bool, len(patterns))
+	matches := func(name string) bool {
+		found := false
+		for i, p := range patterns {
+			if name == p || strings.HasPrefix(name, p+"/") {
+				matched[i] = true
+				found = true
+			}
+		}
+		return found
+	}
+
+	headFiles := make(map[string]*object.File)
+	if head, err := r.Head(); err == nil {
+		c, err := r.CommitObject(head.Hash())
+		if err != nil {
+			return nil, err
+		}
+		tree, err := c.Tree()
+		if err != nil {
+			return nil, err
+		}
+		err = tree.Files().ForEach(func(f *object.File) error {
+			headFiles[f.Name] = f
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, err
+	}
+
+	limited := &index.Index{Version: idx.Version}
+	for _, e := range idx.Entries {
+		if matches(e.Name) {
+			entry := *e
+			limited.Entries = append(limited.Entries, &entry)
+		}
+	}
+	for name, f := range headFiles {
+		if !matches(name) {
+			limited.Entries = append(limited.Entries, &index.Entry{Name: name, Hash: f.Hash, Mode: f.Mode})
+		}
+	}
+
+	for i, p := range patterns {
+		if !matched[i] {
+			return nil, fmt.Errorf("pathspec '%s' did not match any files", p)
+		}
+	}
+	sort.Slice(limited.Entries, func(i, j int) bool {
+		return limited.Entries[i].Name < limited.Entries[j].Name
+	})
+	return limited, nil
+}
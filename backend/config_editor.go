@@ -0,0 +1,389 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// GitConfigEntry is a config value as shown in the settings modal, with the
+// scope and file it is defined in.
+type GitConfigEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Scope string `json:"scope"`
+	File  string `json:"file"`
+}
+
+var (
+	configSectionRegex = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+	configNameRegex    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+)
+
+// configValidators checks the values of well-known keys before they are
+// written. Keys are lower-cased.
+var configValidators = map[string]func(string) error{
+	"core.autocrlf":        oneOf("true", "false", "input"),
+	"core.filemode":        validateBool,
+	"core.ignorecase":      validateBool,
+	"core.symlinks":        validateBool,
+	"core.eol":             oneOf("lf", "crlf", "native"),
+	"pull.rebase":          boolOr("merges", "m", "interactive", "i"),
+	"pull.ff":              boolOr("only"),
+	"fetch.prune":          validateBool,
+	"push.default":         oneOf("nothing", "current", "upstream", "tracking", "simple", "matching"),
+	"push.autosetupremote": validateBool,
+	"commit.gpgsign":       validateBool,
+	"tag.gpgsign":          validateBool,
+	"gpg.format":           oneOf("openpgp", "x509", "ssh"),
+	"merge.ff":             boolOr("only"),
+	"rebase.autostash":     validateBool,
+	"user.email":           validateEmail,
+	"init.defaultbranch":   validateBranchName,
+}
+
+// ListGitConfig returns every config value visible in the repository (or only
+// system and global values for an empty repoPath), in the order git reads
+// them. Later values override earlier ones.
+func (a *App) ListGitConfig(repoPath string) ([]GitConfigEntry, error) {
+	if repoPath != "" {
+		mu := getRepoMutex(repoPath)
+		mu.Lock()
+		defer mu.Unlock()
+	}
+
+	cfg, err := loadGitConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []GitConfigEntry{}
+	for _, e := range cfg.Entries {
+		result = append(result, GitConfigEntry{
+			Key:   e.Key(),
+			Value: e.Value,
+			Scope: e.Scope,
+			File:  e.File,
+		})
+	}
+	return result, nil
+}
+
+// ValidateGitConfig checks a key name and, for well-known keys, its value.
+func (a *App) ValidateGitConfig(key string, value string) error {
+	return validateConfig(key, value)
+}
+
+// SetGitConfig sets a single-valued key in the given scope. Like git config,
+// it refuses to overwrite a key that has multiple values in that scope.
+func (a *App) SetGitConfig(repoPath string, scope string, key string, value string) error {
+	if err := validateConfig(key, value); err != nil {
+		return err
+	}
+	return a.editConfig(repoPath, scope, key, func(existing []string) ([]string, error) {
+		if len(existing) > 1 {
+			return nil, fmt.Errorf("%s has multiple values in the %s config, edit them as a list", key, scope)
+		}
+		return []string{value}, nil
+	})
+}
+
+// AddGitConfigValue adds another value to a multi-valued key.
+func (a *App) AddGitConfigValue(repoPath string, scope string, key string, value string) error {
+	if err := validateConfig(key, value); err != nil {
+		return err
+	}
+	return a.editConfig(repoPath, scope, key, func(existing []string) ([]string, error) {
+		return append(existing, value), nil
+	})
+}
+
+// SetGitConfigValues replaces all values of a multi-valued key.
+func (a *App) SetGitConfigValues(repoPath string, scope string, key string, values []string) error {
+	for _, v := range values {
+		if err := validateConfig(key, v); err != nil {
+			return err
+		}
+	}
+	return a.editConfig(repoPath, scope, key, func(existing []string) ([]string, error) {
+		return values, nil
+	})
+}
+
+// UnsetGitConfig removes all values of a key from the given scope.
+func (a *App) UnsetGitConfig(repoPath string, scope string, key string) error {
+	if err := validateConfigKey(key); err != nil {
+		return err
+	}
+	return a.editConfig(repoPath, scope, key, func(existing []string) ([]string, error) {
+		if len(existing) == 0 {
+			return nil, fmt.Errorf("%s is not set in the %s config", key, scope)
+		}
+		return nil, nil
+	})
+}
+
+// RenameGitConfigKey moves all values of oldKey to newKey within a scope,
+// e.g. to rename an alias.
+func (a *App) RenameGitConfigKey(repoPath string, scope string, oldKey string, newKey string) error {
+	if err := validateConfigKey(oldKey); err != nil {
+		return err
+	}
+	if err := validateConfigKey(newKey); err != nil {
+		return err
+	}
+
+	var values []string
+	err := a.editConfig(repoPath, scope, oldKey, func(existing []string) ([]string, error) {
+		if len(existing) == 0 {
+			return nil, fmt.Errorf("%s is not set in the %s config", oldKey, scope)
+		}
+		values = existing
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+	return a.editConfig(repoPath, scope, newKey, func(existing []string) ([]string, error) {
+		return append(existing, values...), nil
+	})
+}
+
+// editConfig rewrites the values of a key in the file git writes to for the
+// scope. update receives the current values in that file and returns the
+// new ones.
+func (a *App) editConfig(repoPath string, scope string, key string, update func(existing []string) ([]string, error)) error {
+	if repoPath != "" {
+		mu := getRepoMutex(repoPath)
+		mu.Lock()
+		defer mu.Unlock()
+	}
+
+	path, err := configFileForScope(repoPath, scope)
+	if err != nil {
+		return err
+	}
+	return updateConfigFile(path, key, update)
+}
+
+func configFileForScope(repoPath string, scope string) (string, error) {
+	gitDir := ""
+	switch scope {
+	case ScopeLocal:
+		if repoPath == "" {
+			return "", errors.New("local config requires a repository")
+		}
+		var err error
+		gitDir, err = findGitDir(repoPath)
+		if err != nil {
+			return "", err
+		}
+	case ScopeGlobal, ScopeSystem:
+	default:
+		return "", fmt.Errorf("unknown config scope: %s", scope)
+	}
+	return writableConfigFile(scope, gitDir)
+}
+
+// updateConfigFile edits a single config file in place. Existing values are
+// replaced where they are, so comments and layout of the file are kept; new
+// values go after the last value of the key or into the last matching
+// section, which is created if needed.
+func updateConfigFile(path string, key string, update func(existing []string) ([]string, error)) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	content := string(data)
+
+	entries, sections, err := parseConfig(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	section, subsection, name := splitConfigKey(key)
+	rawName := key[strings.LastIndex(key, ".")+1:]
+
+	var matches []configEntry
+	var existing []string
+	for _, e := range entries {
+		if e.Section == section && e.Subsection == subsection && e.Name == name {
+			matches = append(matches, e)
+			existing = append(existing, e.Value)
+		}
+	}
+
+	values, err := update(existing)
+	if err != nil {
+		return err
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+
+	for i, e := range matches {
+		if i < len(values) {
+			edits = append(edits, edit{e.start, e.end, rawName + " = " + formatConfigValue(values[i])})
+			continue
+		}
+		// Remove the whole line if the entry is alone on it.
+		start, end := e.start, e.end
+		lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+		if strings.TrimSpace(content[lineStart:start]) == "" {
+			start = lineStart
+			if end < len(content) && content[end] == '\n' {
+				end++
+			}
+		}
+		edits = append(edits, edit{start, end, ""})
+	}
+
+	if len(values) > len(matches) {
+		var lines strings.Builder
+		for _, v := range values[len(matches):] {
+			lines.WriteString("\n\t" + rawName + " = " + formatConfigValue(v))
+		}
+
+		insertAt := -1
+		if len(matches) > 0 {
+			insertAt = matches[len(matches)-1].end
+		} else {
+			for _, s := range sections {
+				if s.Section == section && s.Subsection == subsection {
+					insertAt = s.end
+				}
+			}
+		}
+
+		if insertAt >= 0 {
+			edits = append(edits, edit{insertAt, insertAt, lines.String()})
+		} else {
+			prefix := ""
+			if content != "" && !strings.HasSuffix(content, "\n") {
+				prefix = "\n"
+			}
+			edits = append(edits, edit{len(content), len(content), prefix + formatSectionHeader(section, subsection) + lines.String() + "\n"})
+		}
+	}
+
+	if len(edits) == 0 {
+		return nil
+	}
+
+	// Apply from the end so earlier offsets stay valid.
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		content = content[:e.start] + e.text + content[e.end:]
+	}
+
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func formatSectionHeader(section, subsection string) string {
+	if subsection == "" {
+		return "[" + section + "]"
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(subsection)
+	return fmt.Sprintf("[%s \"%s\"]", section, escaped)
+}
+
+// formatConfigValue escapes a value and quotes it when it would otherwise be
+// changed by the parser (surrounding whitespace, comment characters).
+func formatConfigValue(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(value)
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, "#;") {
+		return `"` + escaped + `"`
+	}
+	return escaped
+}
+
+func validateConfig(key string, value string) error {
+	if err := validateConfigKey(key); err != nil {
+		return err
+	}
+	section, subsection, name := splitConfigKey(key)
+	if subsection != "" {
+		return nil
+	}
+	if validate, ok := configValidators[section+"."+name]; ok {
+		if err := validate(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func validateConfigKey(key string) error {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first <= 0 || last == len(key)-1 {
+		return fmt.Errorf("invalid key %q: expected section.name", key)
+	}
+	if !configSectionRegex.MatchString(key[:first]) {
+		return fmt.Errorf("invalid section name in key %q", key)
+	}
+	if !configNameRegex.MatchString(key[last+1:]) {
+		return fmt.Errorf("invalid variable name in key %q", key)
+	}
+	if strings.Contains(key[first:last], "\n") {
+		return fmt.Errorf("invalid subsection in key %q", key)
+	}
+	return nil
+}
+
+func oneOf(allowed ...string) func(string) error {
+	return func(value string) error {
+		for _, a := range allowed {
+			if strings.EqualFold(value, a) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+func validateBool(value string) error {
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "1", "0", "":
+		return nil
+	}
+	if _, err := strconv.Atoi(value); err == nil {
+		return nil
+	}
+	return errors.New("expected a boolean")
+}
+
+// boolOr accepts a boolean or one of the given keywords, like pull.ff does
+// with "only".
+func boolOr(keywords ...string) func(string) error {
+	keyword := oneOf(keywords...)
+	return func(value string) error {
+		if validateBool(value) == nil || keyword(value) == nil {
+			return nil
+		}
+		return fmt.Errorf("expected a boolean or one of %s", strings.Join(keywords, ", "))
+	}
+}
+
+func validateEmail(value string) error {
+	if value != "" && !strings.Contains(value, "@") {
+		return errors.New("expected an email address")
+	}
+	return nil
+}
+
+func validateBranchName(value string) error {
+	if !plumbing.NewBranchReferenceName(value).IsBranch() || value == "" ||
+		strings.ContainsAny(value, " ~^:?*[\\") || strings.Contains(value, "..") {
+		return errors.New("expected a valid branch name")
+	}
+	return nil
+}
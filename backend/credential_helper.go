@@ -0,0 +1,348 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// helperCredential is a credential given by a git credential helper. Like
+// git, the app tells the helpers whether it worked.
+type helperCredential struct {
+	*githttp.BasicAuth
+}
+
+// credentialQuery is what is sent to and read from a credential helper,
+// following the git credential protocol.
+type credentialQuery struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// credentialHelpers returns the helpers configured for a URL in the order git
+// runs them: the values of credential.helper and credential.<url>.helper for
+// every <url> matching the URL. An empty value clears the helpers before it.
+func credentialHelpers(gc *gitConfig, remoteURL string) []string {
+	var helpers []string
+	for _, e := range gc.Entries {
+		if e.Section != "credential" || e.Name != "helper" {
+			continue
+		}
+		if e.Subsection != "" && !credentialURLMatches(e.Subsection, remoteURL) {
+			continue
+		}
+		if e.Value == "" {
+			helpers = nil
+			continue
+		}
+		helpers = append(helpers, e.Value)
+	}
+	return helpers
+}
+
+// credentialSetting returns the last value of credential.<name> or
+// credential.<url>.<name> for a URL. Names are case-insensitive.
+func credentialSetting(gc *gitConfig, remoteURL string, name string) (string, bool) {
+	name = strings.ToLower(name)
+	value, found := "", false
+	for _, e := range gc.Entries {
+		if e.Section != "credential" || e.Name != name {
+			continue
+		}
+		if e.Subsection != "" && !credentialURLMatches(e.Subsection, remoteURL) {
+			continue
+		}
+		value, found = e.Value, true
+	}
+	return value, found
+}
+
+// credentialURLMatches matches a URL against the <url> of a credential.<url>
+// section like git's http.<url> matching: the scheme, host and port must be
+// the same, a "*" label in the host matches any label, the path of the
+// pattern must be a prefix of the URL's path and a username in the pattern
+// must match. A pattern without a scheme only names the host.
+func credentialURLMatches(pattern string, remoteURL string) bool {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return false
+	}
+	if !strings.Contains(pattern, "://") {
+		return matchCredentialHost(pattern, u.Hostname())
+	}
+	p, err := url.Parse(pattern)
+	if err != nil {
+		return false
+	}
+
+	if !strings.EqualFold(p.Scheme, u.Scheme) || !matchCredentialHost(p.Hostname(), u.Hostname()) {
+		return false
+	}
+	if defaultPort(p) != defaultPort(u) {
+		return false
+	}
+	if p.User != nil && (u.User == nil || p.User.Username() != u.User.Username()) {
+		return false
+	}
+	prefix := strings.TrimSuffix(p.Path, "/")
+	return prefix == "" || u.Path == prefix || strings.HasPrefix(u.Path, prefix+"/")
+}
+
+func matchCredentialHost(pattern string, host string) bool {
+	patternLabels := strings.Split(strings.ToLower(pattern), ".")
+	hostLabels := strings.Split(strings.ToLower(host), ".")
+	if len(patternLabels) != len(hostLabels) {
+		return false
+	}
+	for i, label := range patternLabels {
+		if label != "*" && label != hostLabels[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func defaultPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
+
+// newCredentialQuery describes a URL for the helpers. The path is only sent
+// with credential.useHttpPath, like git does.
+func newCredentialQuery(gc *gitConfig, remoteURL string) (credentialQuery, error) {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return credentialQuery{}, err
+	}
+	q := credentialQuery{Protocol: u.Scheme, Host: u.Host}
+	if v, ok := credentialSetting(gc, remoteURL, "useHttpPath"); ok && parseConfigBool(v) {
+		q.Path = strings.TrimPrefix(u.Path, "/")
+	}
+	if u.User != nil {
+		q.Username = u.User.Username()
+	} else if v, ok := credentialSetting(gc, remoteURL, "username"); ok {
+		q.Username = v
+	}
+	return q, nil
+}
+
+// helperGet asks the helpers for a credential for a URL. The first helper
+// that gives a username and password wins; a helper answering quit stops the
+// search. Helpers that fail are skipped, like git does.
+func helperGet(ctx context.Context, repoPath string, remoteURL string) (*helperCredential, error) {
+	gc, helpers := repoCredentialHelpers(repoPath, remoteURL)
+	if len(helpers) == 0 {
+		return nil, nil
+	}
+	q, err := newCredentialQuery(gc, remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, helper := range helpers {
+		out, err := runCredentialHelper(ctx, helper, "get", q)
+		if err != nil {
+			continue
+		}
+		if out["username"] != "" {
+			q.Username = out["username"]
+		}
+		if out["password"] != "" {
+			q.Password = out["password"]
+		}
+		if q.Username != "" && q.Password != "" {
+			return &helperCredential{&githttp.BasicAuth{Username: q.Username, Password: q.Password}}, nil
+		}
+		if v, ok := out["quit"]; ok && parseConfigBool(v) {
+			break
+		}
+	}
+	return nil, nil
+}
+
+// helperUpdate sends a credential to every helper with action "store" after
+// it worked or "erase" after it was rejected. It reports whether any helper
+// is configured.
+func helperUpdate(ctx context.Context, repoPath string, remoteURL string, action string, username string, password string) bool {
+	gc, helpers := repoCredentialHelpers(repoPath, remoteURL)
+	if len(helpers) == 0 {
+		return false
+	}
+	q, err := newCredentialQuery(gc, remoteURL)
+	if err != nil {
+		return true
+	}
+	q.Username, q.Password = username, password
+	for _, helper := range helpers {
+		_, _ = runCredentialHelper(ctx, helper, action, q)
+	}
+	return true
+}
+
+// repoCredentialHelpers returns the config of a repository and its helpers
+// for a URL. For a directory that is not a repository yet, e.g. the target
+// of a clone, only the system and global config apply.
+func repoCredentialHelpers(repoPath string, remoteURL string) (*gitConfig, []string) {
+	gc, err := loadGitConfig(repoPath)
+	if err != nil {
+		if gc, err = loadGitConfig(""); err != nil {
+			return nil, nil
+		}
+	}
+	return gc, credentialHelpers(gc, remoteURL)
+}
+
+// runCredentialHelper runs a helper like git: "!cmd" runs cmd in the shell,
+// an absolute path runs that program and any other name runs
+// git credential-<name>. Arguments of the helper are kept; only "!" helpers
+// need a shell, the sh of Git for Windows on Windows (see gitShell). The
+// helper's answer is returned as key/value pairs.
+func runCredentialHelper(ctx context.Context, helper string, action string, q credentialQuery) (map[string]string, error) {
+	var cmd *exec.Cmd
+	if strings.HasPrefix(helper, "!") {
+		sh, err := gitShell()
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, sh, "-c", helper[1:]+" "+action)
+	} else {
+		args, err := splitHelperArgs(helper)
+		if err != nil || len(args) == 0 {
+			return nil, fmt.Errorf("invalid credential helper %q", helper)
+		}
+		args = append(args, action)
+		if isAbsoluteHelper(args[0]) {
+			cmd = exec.CommandContext(ctx, args[0], args[1:]...)
+		} else {
+			cmd = exec.CommandContext(ctx, "git", append([]string{"credential-" + args[0]}, args[1:]...)...)
+		}
+	}
+
+	var input bytes.Buffer
+	for _, kv := range [][2]string{
+		{"protocol", q.Protocol},
+		{"host", q.Host},
+		{"path", q.Path},
+		{"username", q.Username},
+		{"password", q.Password},
+	} {
+		if kv[1] == "" {
+			continue
+		}
+		// A newline would let a value inject other attributes
+		if strings.ContainsAny(kv[1], "\n\x00") {
+			return nil, fmt.Errorf("invalid credential %s", kv[0])
+		}
+		fmt.Fprintf(&input, "%s=%s\n", kv[0], kv[1])
+	}
+	input.WriteString("\n")
+
+	cmd.Stdin = &input
+	// Helpers must not ask on a terminal the app does not have
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	// Children of a killed helper can keep its output open
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("credential helper %s failed: %w", helper, err)
+		}
+		return nil, err
+	}
+
+	result := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+// splitHelperArgs splits a helper and its arguments into words like the shell
+// git would run it with: words are separated by blanks, quotes and
+// backslashes escape them, and a leading ~/ is the home directory.
+func splitHelperArgs(helper string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord, tilde, quote := false, false, rune(0)
+	finish := func() {
+		w := word.String()
+		if tilde {
+			w = expandHome(w)
+		}
+		args = append(args, w)
+		word.Reset()
+		inWord, tilde = false, false
+	}
+	runes := []rune(helper)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote == '\'' && c == '\'', quote == '"' && c == '"':
+			quote = 0
+		case quote == '\'':
+			word.WriteRune(c)
+		case c == '\\' && (quote == 0 || i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1])):
+			if i+1 == len(runes) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote == '"':
+			word.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t':
+			if inWord {
+				finish()
+			}
+		default:
+			if !inWord && c == '~' {
+				tilde = true
+			}
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		finish()
+	}
+	return args, nil
+}
+
+func isAbsoluteHelper(helper string) bool {
+	// Git for Windows also accepts drive paths such as C:/...
+	return strings.HasPrefix(helper, "/") ||
+		len(helper) > 2 && helper[1] == ':' && (helper[2] == '/' || helper[2] == '\\')
+}
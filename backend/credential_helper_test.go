@@ -0,0 +1,43 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewCredentialQueryUseHttpPath(t *testing.T) {
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+
+	tests := []struct {
+		config string
+		path   string
+	}{
+		{"", ""},
+		{"[credential]\n\tuseHttpPath = true\n", "team/repo.git"},
+		{"[credential \"https://example.com\"]\n\tuseHttpPath = true\n", "team/repo.git"},
+		{"[credential \"https://other.example.com\"]\n\tuseHttpPath = true\n", ""},
+		{"[credential]\n\tuseHttpPath = true\n\tusehttppath = false\n", ""},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(global, []byte(tt.config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		gc, err := loadGitConfig("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := newCredentialQuery(gc, "https://example.com/team/repo.git")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Path != tt.path {
+			t.Errorf("config %q: path = %q, want %q", tt.config, q.Path, tt.path)
+		}
+		if q.Protocol != "https" || q.Host != "example.com" {
+			t.Errorf("config %q: protocol %q, host %q", tt.config, q.Protocol, q.Host)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package backend
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Credential is a username and password or access token for HTTP(S)
+// remotes. URL is the scheme and host it is used for, e.g.
+// https://gitlab.example.com.
+type Credential struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// StoredCredential describes a saved credential without its password.
+type StoredCredential struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+}
+
+// CredentialRequest is sent to the frontend in a "credential-request" event
+// when a server asks for credentials. The frontend answers with
+// ProvideCredential. Failed is set when the credentials just used were
+// rejected. Helpers lists the git credential helpers that remembered
+// credentials are saved to instead of the app's store.
+type CredentialRequest struct {
+	ID       string   `json:"id"`
+	URL      string   `json:"url"`
+	Host     string   `json:"host"`
+	Username string   `json:"username"`
+	Failed   bool     `json:"failed"`
+	Helpers  []string `json:"helpers"`
+}
+
+// CredentialResponse answers a CredentialRequest. Remember saves the
+// credential once the server has accepted it; Cancel gives up.
+type CredentialResponse struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Remember bool   `json:"remember"`
+	Cancel   bool   `json:"cancel"`
+}
+
+var errCredentialCancelled = errors.New("no credentials were given")
+
+// The credentials entered since the app started, by credential URL. Like a
+// git credential cache, they are used again without asking.
+var sessionCredentials struct {
+	sync.Mutex
+	creds map[string]Credential
+}
+
+// The credential prompts waiting for an answer from the frontend.
+var credentialPrompts struct {
+	sync.Mutex
+	pending map[string]chan CredentialResponse
+	next    uint64
+}
+
+// credentialsMu guards the credential store file.
+var credentialsMu sync.Mutex
+
+// Whether the user was told that the credential store cannot be read.
+// Guarded by credentialsMu.
+var credentialStoreWarned bool
+
+// GetStoredCredentials lists the saved HTTPS credentials.
+func (a *App) GetStoredCredentials() ([]StoredCredential, error) {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	creds, err := readCredentials()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]StoredCredential, 0, len(creds))
+	for _, c := range creds {
+		result = append(result, StoredCredential{URL: c.URL, Username: c.Username})
+	}
+	return result, nil
+}
+
+// DeleteStoredCredential removes the saved credential of a host, and forgets
+// it for the rest of the session too.
+func (a *App) DeleteStoredCredential(credentialURL string, username string) error {
+	sessionCredentials.Lock()
+	if c, ok := sessionCredentials.creds[credentialURL]; ok && c.Username == username {
+		delete(sessionCredentials.creds, credentialURL)
+	}
+	sessionCredentials.Unlock()
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	creds, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	kept := creds[:0]
+	for _, c := range creds {
+		if c.URL != credentialURL || c.Username != username {
+			kept = append(kept, c)
+		}
+	}
+	return writeCredentials(kept)
+}
+
+// ResetStoredCredentials deletes all saved credentials and their key, e.g.
+// when the store cannot be read anymore because its key was lost. The next
+// saved credential starts a new store.
+func (a *App) ResetStoredCredentials() error {
+	sessionCredentials.Lock()
+	sessionCredentials.creds = nil
+	sessionCredentials.Unlock()
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	p, err := credentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	credentialStoreWarned = false
+	return deleteCredentialKey()
+}
+
+// ProvideCredential answers a credential request of the backend.
+func (a *App) ProvideCredential(id string, response CredentialResponse) error {
+	credentialPrompts.Lock()
+	ch, ok := credentialPrompts.pending[id]
+	delete(credentialPrompts.pending, id)
+	credentialPrompts.Unlock()
+	if !ok {
+		return fmt.Errorf("no pending credential request %s", id)
+	}
+	ch <- response
+	return nil
+}
+
+// httpAuth returns the credentials for an HTTP(S) URL: those in the URL
+// itself, else the ones entered this session, else the saved ones, else the
+// ones of the configured git credential helpers. Without any, the request is
+// sent without credentials and withAuth asks for them if the server wants
+// some. A credential store that cannot be read counts as empty, and the user
+// is told once.
+func (a *App) httpAuth(ctx context.Context, repoPath string, remoteURL string) (transport.AuthMethod, error) {
+	host, username, password, err := credentialURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	if password != "" {
+		// go-git uses the credentials of the URL
+		return nil, nil
+	}
+
+	if c, ok := sessionCredential(host, username); ok {
+		return c.basicAuth(), nil
+	}
+
+	credentialsMu.Lock()
+	creds, err := readCredentials()
+	warn := err != nil && !credentialStoreWarned
+	if warn {
+		credentialStoreWarned = true
+	}
+	credentialsMu.Unlock()
+	if warn {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("The saved credentials were skipped: %v", err),
+			Percent: -1,
+		})
+	}
+	for _, c := range creds {
+		if c.URL == host && (username == "" || c.Username == username) {
+			return c.basicAuth(), nil
+		}
+	}
+
+	c, err := helperGet(ctx, repoPath, remoteURL)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// withAuth runs fn, which talks to remoteURL, with auth. When an HTTP(S)
+// server rejects the request for lack of credentials, the user is asked for
+// a username and password or token and fn runs again, until it succeeds or
+// the user gives up. Credentials that worked are kept for the session and
+// saved if the user asked for it.
+//
+// Like git, credentials that worked are given to the configured credential
+// helpers to store and rejected ones to erase. Remembered credentials go to
+// the helpers if there are any, else to the app's store.
+func (a *App) withAuth(ctx context.Context, repoPath string, remoteURL string, auth transport.AuthMethod, fn func(transport.AuthMethod) error) error {
+	err := fn(auth)
+	if !isHTTPURL(remoteURL) {
+		return err
+	}
+	if c, ok := auth.(*helperCredential); ok && credentialAccepted(err) {
+		helperUpdate(ctx, repoPath, remoteURL, "store", c.Username, c.Password)
+	}
+
+	for credentialRejected(err) {
+		a.rejectCredential(ctx, repoPath, remoteURL, auth)
+
+		cred, remember, perr := a.promptCredential(ctx, repoPath, remoteURL, auth != nil)
+		if perr != nil {
+			if errors.Is(perr, errCredentialCancelled) {
+				return err
+			}
+			return perr
+		}
+		auth = cred.basicAuth()
+		if err = fn(auth); !credentialAccepted(err) {
+			continue
+		}
+
+		sessionCredentials.Lock()
+		if sessionCredentials.creds == nil {
+			sessionCredentials.creds = map[string]Credential{}
+		}
+		sessionCredentials.creds[cred.URL] = cred
+		sessionCredentials.Unlock()
+		if !remember {
+			continue
+		}
+		if helperUpdate(ctx, repoPath, remoteURL, "store", cred.Username, cred.Password) {
+			continue
+		}
+		if err := storeCredential(cred); err != nil {
+			runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+				Status:  fmt.Sprintf("The credentials could not be saved: %v", err),
+				Percent: -1,
+			})
+		}
+	}
+	return err
+}
+
+func credentialRejected(err error) bool {
+	return errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed)
+}
+
+// credentialAccepted reports whether a request got past authentication.
+func credentialAccepted(err error) bool {
+	return err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) || errors.Is(err, transport.ErrEmptyRemoteRepository)
+}
+
+// rejectCredential forgets credentials the server rejected for this session
+// and has the credential helpers erase them, like git does.
+func (a *App) rejectCredential(ctx context.Context, repoPath string, remoteURL string, auth transport.AuthMethod) {
+	var basic *githttp.BasicAuth
+	switch c := auth.(type) {
+	case *githttp.BasicAuth:
+		basic = c
+	case *helperCredential:
+		basic = c.BasicAuth
+	default:
+		return
+	}
+
+	host, _, _, err := credentialURL(remoteURL)
+	if err != nil {
+		return
+	}
+	sessionCredentials.Lock()
+	if c, ok := sessionCredentials.creds[host]; ok && c.Username == basic.Username {
+		delete(sessionCredentials.creds, host)
+	}
+	sessionCredentials.Unlock()
+	helperUpdate(ctx, repoPath, remoteURL, "erase", basic.Username, basic.Password)
+}
+
+// promptCredential asks the frontend for credentials for a URL and waits for
+// the answer. failed tells the user that the last credentials were rejected.
+func (a *App) promptCredential(ctx context.Context, repoPath string, remoteURL string, failed bool) (Credential, bool, error) {
+	host, username, _, err := credentialURL(remoteURL)
+	if err != nil {
+		return Credential{}, false, err
+	}
+	gc, helpers := repoCredentialHelpers(repoPath, remoteURL)
+	if username == "" && gc != nil {
+		username, _ = credentialSetting(gc, remoteURL, "username")
+	}
+	if helpers == nil {
+		helpers = []string{}
+	}
+
+	ch := make(chan CredentialResponse, 1)
+	credentialPrompts.Lock()
+	if credentialPrompts.pending == nil {
+		credentialPrompts.pending = map[string]chan CredentialResponse{}
+	}
+	credentialPrompts.next++
+	id := fmt.Sprintf("credential-%d", credentialPrompts.next)
+	credentialPrompts.pending[id] = ch
+	credentialPrompts.Unlock()
+	defer func() {
+		credentialPrompts.Lock()
+		delete(credentialPrompts.pending, id)
+		credentialPrompts.Unlock()
+	}()
+
+	runtime.EventsEmit(a.ctx, "credential-request", CredentialRequest{
+		ID:       id,
+		URL:      redactURL(remoteURL),
+		Host:     host,
+		Username: username,
+		Failed:   failed,
+		Helpers:  helpers,
+	})
+
+	select {
+	case resp := <-ch:
+		if resp.Cancel || resp.Username == "" && resp.Password == "" {
+			return Credential{}, false, errCredentialCancelled
+		}
+		return Credential{URL: host, Username: resp.Username, Password: resp.Password}, resp.Remember, nil
+	case <-ctx.Done():
+		runtime.EventsEmit(a.ctx, "credential-request-cancelled", id)
+		return Credential{}, false, ctx.Err()
+	}
+}
+
+func (c Credential) basicAuth() transport.AuthMethod {
+	return &githttp.BasicAuth{Username: c.Username, Password: c.Password}
+}
+
+func sessionCredential(host string, username string) (Credential, bool) {
+	sessionCredentials.Lock()
+	defer sessionCredentials.Unlock()
+	c, ok := sessionCredentials.creds[host]
+	if !ok || username != "" && c.Username != username {
+		return Credential{}, false
+	}
+	return c, true
+}
+
+func isHTTPURL(remoteURL string) bool {
+	return strings.HasPrefix(remoteURL, "https://") || strings.HasPrefix(remoteURL, "http://")
+}
+
+// credentialURL splits an HTTP(S) URL into the URL credentials are stored
+// under (scheme and host, like git without credential.useHttpPath) and the
+// username and password it contains.
+func credentialURL(remoteURL string) (host string, username string, password string, err error) {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+	return u.Scheme + "://" + u.Host, username, password, nil
+}
+
+// redactURL removes the password from a URL for display.
+func redactURL(remoteURL string) string {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return remoteURL
+	}
+	return u.Redacted()
+}
+
+// storeCredential saves a credential, replacing any other for the same host
+// and username.
+func storeCredential(cred Credential) error {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	creds, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, c := range creds {
+		if c.URL == cred.URL && c.Username == cred.Username {
+			creds[i] = cred
+			replaced = true
+		}
+	}
+	if !replaced {
+		creds = append(creds, cred)
+	}
+	sort.SliceStable(creds, func(i, j int) bool {
+		return creds[i].URL < creds[j].URL
+	})
+	return writeCredentials(creds)
+}
+
+// The credential store is a JSON list of credentials encrypted with AES-GCM.
+// The key is kept in the OS keyring (see credentialKey).
+
+// readCredentials reads the credential store. An error means that the store
+// exists but cannot be read, which ResetStoredCredentials gets out of.
+func readCredentials() ([]Credential, error) {
+	creds, err := decryptCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("%w (reset the saved credentials in the settings to start over)", err)
+	}
+	return creds, nil
+}
+
+func decryptCredentials() ([]Credential, error) {
+	p, err := credentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return []Credential{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := credentialKey(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := credentialCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is corrupt", p)
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("the saved credentials cannot be decrypted: %w", err)
+	}
+
+	var creds []Credential
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return creds, nil
+}
+
+func writeCredentials(creds []Credential) error {
+	p, err := credentialsPath()
+	if err != nil {
+		return err
+	}
+	if len(creds) == 0 {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	key, err := credentialKey(true)
+	if err != nil {
+		return err
+	}
+	gcm, err := credentialCipher(key)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(p, gcm.Seal(nonce, nonce, plain, nil), 0600)
+}
+
+func credentialCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func credentialsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "celerix-git", "credentials.enc"), nil
+}
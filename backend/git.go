@@ -1,11 +1,13 @@
 package backend
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,7 +16,9 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v5/utils/merkletrie"
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -34,6 +38,7 @@ func getRepoMutex(path string) *sync.Mutex {
 
 type GitRemoteBranches struct {
 	Name     string   `json:"name"`
+	URL      string   `json:"url"`
 	Branches []string `json:"branches"`
 }
 
@@ -51,6 +56,7 @@ type RepoStats struct {
 	Tags          []string            `json:"tags"`
 	Stashes       []string            `json:"stashes"`
 	CurrentBranch string              `json:"currentBranch"`
+	Tracking      []BranchTracking    `json:"tracking"`
 }
 
 type GitStatusFile struct {
@@ -60,14 +66,64 @@ type GitStatusFile struct {
 }
 
 type GitCommit struct {
-	Hash         string    `json:"hash"`
-	AuthorName   string    `json:"authorName"`
-	AuthorEmail  string    `json:"authorEmail"`
-	Date         time.Time `json:"date"`
-	Subject      string    `json:"subject"`
-	Body         string    `json:"body"`
-	ParentHashes []string  `json:"parentHashes"`
-	Refs         []string  `json:"refs"`
+	Hash         string          `json:"hash"`
+	AuthorName   string          `json:"authorName"`
+	AuthorEmail  string          `json:"authorEmail"`
+	Date         time.Time       `json:"date"`
+	Subject      string          `json:"subject"`
+	Body         string          `json:"body"`
+	ParentHashes []string        `json:"parentHashes"`
+	Refs         []string        `json:"refs"`
+	Notes        []GitNote       `json:"notes"`
+	Trailers     []CommitTrailer `json:"trailers"`
+	Signature    SignatureInfo   `json:"signature"`
+	// OnProtectedBranch is set for commits reachable from a protected branch
+	// (see SetProtectedBranches).
+	OnProtectedBranch bool `json:"onProtectedBranch"`
+}
+
+// GitTag is a tag with its target commit and, for annotated tags, the tag
+// object's details and signature.
+type GitTag struct {
+	Name        string        `json:"name"`
+	Hash        string        `json:"hash"`
+	CommitHash  string        `json:"commitHash"`
+	Annotated   bool          `json:"annotated"`
+	Message     string        `json:"message"`
+	TaggerName  string        `json:"taggerName"`
+	TaggerEmail string        `json:"taggerEmail"`
+	Date        time.Time     `json:"date"`
+	Signature   SignatureInfo `json:"signature"`
+}
+
+// CommitOptions describes a commit created through CommitWithOptions.
+// Trailers are appended to the message in canonical form; SignOff adds a
+// Signed-off-by trailer for the committer. NoVerify skips the pre-commit and
+// commit-msg hooks, but not the commit message rules of the repository.
+type CommitOptions struct {
+	Subject  string          `json:"subject"`
+	Body     string          `json:"body"`
+	Amend    bool            `json:"amend"`
+	Trailers []CommitTrailer `json:"trailers"`
+	SignOff  bool            `json:"signOff"`
+	// Sign signs the commit even if commit.gpgSign is not set.
+	Sign     bool `json:"sign"`
+	NoVerify bool `json:"noVerify"`
+	// AllowEmpty creates the commit even if it records no changes.
+	AllowEmpty bool `json:"allowEmpty"`
+	// FixupCommit creates a fixup, squash or amend commit (FixupKind) for the
+	// commit, to be folded into it by an autosquash rebase.
+	FixupCommit string `json:"fixupCommit"`
+	FixupKind   string `json:"fixupKind"`
+	// AuthorName, AuthorEmail and AuthorDate override the author. The date is
+	// RFC 3339 or git's "2006-01-02 15:04:05 -0700"; without a zone it is
+	// local time.
+	AuthorName  string `json:"authorName"`
+	AuthorEmail string `json:"authorEmail"`
+	AuthorDate  string `json:"authorDate"`
+	// Paths limits the commit to the staged changes of these files and
+	// directories. Other staged changes stay in the index.
+	Paths []string `json:"paths"`
 }
 
 type CommitFileChange struct {
@@ -91,10 +147,16 @@ func (a *App) IsGitRepo(path string) (bool, error) {
 	return true, nil
 }
 
-func (a *App) GetRepoStats(path string) (*RepoStats, error) {
+func (a *App) GetRepoStats(path string) (_ *RepoStats, err error) {
+	op := a.startOperation(OperationStats, path)
+	defer func() { err = a.finishOperation(op, err) }()
+
 	mu := getRepoMutex(path)
 	mu.Lock()
 	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// 1. Open the repository
 	r, err := git.PlainOpen(path)
@@ -112,27 +174,42 @@ func (a *App) GetRepoStats(path string) (*RepoStats, error) {
 		dotGitPath = path
 	}
 
-	size, _ := a.dirSize(dotGitPath)
+	size, err := a.dirSize(op.ctx, dotGitPath)
+	if op.ctx.Err() != nil {
+		return nil, err
+	}
 	stats.SizeMB = float64(size) / 1024 / 1024
 
-	// 3. Get Remote URL
-	if remotes, err := r.Remotes(); err == nil && len(remotes) > 0 {
-		stats.RemoteURL = remotes[0].Config().URLs[0]
+	// 3. Get Remote URL of the default remote
+	if remote, err := resolveRemote(path, r, "", false); err == nil {
+		stats.RemoteURL = remote.Config().URLs[0]
 	}
 
 	// 4. History Stats (Count, First, Last)
-	cIter, err := r.Log(&git.LogOptions{})
-	if err == nil {
-		var count int
-		_ = cIter.ForEach(func(c *object.Commit) error {
-			if count == 0 {
+	if head, err := r.Head(); err == nil {
+		if idx, err := loadHistoryIndex(path, r); err == nil {
+			reachable := idx.reachable(head.Hash())
+			stats.CommitCount = len(reachable)
+
+			if c, err := r.CommitObject(head.Hash()); err == nil {
 				stats.LastCommit = c.Author.When
 			}
-			stats.FirstCommit = c.Author.When
-			count++
-			return nil
-		})
-		stats.CommitCount = count
+			// The first commit is the oldest root commit in the history of HEAD
+			for h := range reachable {
+				if len(idx.Commits[h].Parents) > 0 {
+					continue
+				}
+				if c, err := r.CommitObject(h); err == nil {
+					if stats.FirstCommit.IsZero() || c.Author.When.Before(stats.FirstCommit) {
+						stats.FirstCommit = c.Author.When
+					}
+				}
+			}
+		}
+	}
+
+	if err := op.ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// 5. Worktree Status (Uncommitted changes)
@@ -150,6 +227,10 @@ func (a *App) GetRepoStats(path string) (*RepoStats, error) {
 		}
 	}
 
+	if err := op.ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 6. Branches
 	branchIter, err := r.Branches()
 	if err == nil {
@@ -165,15 +246,26 @@ func (a *App) GetRepoStats(path string) (*RepoStats, error) {
 		stats.CurrentBranch = head.Name().Short()
 	}
 
+	// 6.2 Upstreams and ahead/behind counts
+	if tracking, err := branchTracking(path, r); err == nil {
+		stats.Tracking = tracking
+	}
+
 	// 7. Remotes and Remote Branches
 	remotes, err := r.Remotes()
 	if err == nil {
+		sort.Slice(remotes, func(i, j int) bool {
+			return remotes[i].Config().Name < remotes[j].Config().Name
+		})
 		remoteRefs, _ := r.References()
 		for _, remote := range remotes {
 			remoteName := remote.Config().Name
 			rb := GitRemoteBranches{
 				Name: remoteName,
 			}
+			if urls := remote.Config().URLs; len(urls) > 0 {
+				rb.URL = urls[0]
+			}
 
 			// Find branches for this remote
 			if remoteRefs != nil {
@@ -586,92 +678,131 @@ func (a *App) GetCommitHistory(repoPath string, count int) ([]GitCommit, error)
 		return nil, err
 	}
 
-	// Get all references to map them to commits later
-	refMap := make(map[plumbing.Hash][]string)
-	refs, _ := r.References()
-	if refs != nil {
-		_ = refs.ForEach(func(ref *plumbing.Reference) error {
-			hash := ref.Hash()
-			// For symbolic refs (like HEAD), resolve to actual hash
-			if ref.Type() == plumbing.SymbolicReference {
-				resolved, err := r.Reference(ref.Target(), true)
-				if err == nil {
-					hash = resolved.Hash()
-				}
-			}
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return nil, err
+	}
 
-			// If it's a tag, it might be an annotated tag.
-			// We need to resolve it to the commit hash it points to.
-			if ref.Name().IsTag() {
-				tagObj, err := r.TagObject(hash)
-				if err == nil {
-					// It's an annotated tag
-					commit, err := tagObj.Commit()
-					if err == nil {
-						hash = commit.Hash
-					}
-				}
-				// If r.TagObject fails, it's likely a lightweight tag,
-				// and hash already points to the commit.
-			}
+	ordered, _ := idx.topoOrder(false)
+	if count > 0 && len(ordered) > count {
+		ordered = ordered[:count]
+	}
 
-			name := ref.Name().Short()
-			refMap[hash] = append(refMap[hash], name)
-			return nil
-		})
+	notes := readAllNotes(r)
+	protected := protectedCommits(repoPath, r, idx)
+
+	var commits []GitCommit
+	var objects []*object.Commit
+	for _, h := range ordered {
+		c, err := r.CommitObject(h)
+		if err != nil {
+			return nil, err
+		}
+		commit := newGitCommit(c, idx.Refs)
+		if n, ok := notes[h]; ok {
+			commit.Notes = n
+		}
+		commit.OnProtectedBranch = protected[h]
+		commits = append(commits, commit)
+		objects = append(objects, c)
 	}
 
-	cIter, err := r.Log(&git.LogOptions{
-		Order: git.LogOrderCommitterTime,
-		All:   true,
-	})
+	// Verifying runs gpg or ssh-keygen per commit, so only the newest
+	// commits are verified; older ones keep cached results
+	verified := objects
+	if len(verified) > maxVerifiedCommits {
+		verified = verified[:maxVerifiedCommits]
+	}
+	verifier, err := newSignatureVerifier(repoPath)
 	if err != nil {
 		return nil, err
 	}
+	for i, info := range verifier.verifyCommits(verified) {
+		commits[i].Signature = info
+	}
+	for i := len(verified); i < len(objects); i++ {
+		commits[i].Signature = cachedSignature(objects[i].Hash, objects[i].PGPSignature)
+	}
 
-	var commits []GitCommit
-	err = cIter.ForEach(func(c *object.Commit) error {
-		if count > 0 && len(commits) >= count {
-			return io.EOF
-		}
+	return commits, nil
+}
 
-		var parents []string
-		for _, ph := range c.ParentHashes {
-			parents = append(parents, ph.String())
+// getRefMap maps commit hashes to the short names of all references pointing
+// at them. Symbolic references and annotated tags are resolved to the commit.
+// Notes refs are skipped, their commits are not part of the project history.
+func getRefMap(r *git.Repository) map[plumbing.Hash][]string {
+	refMap := make(map[plumbing.Hash][]string)
+	refs, _ := r.References()
+	if refs == nil {
+		return refMap
+	}
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsNote() {
+			return nil
 		}
 
-		subject := strings.Split(c.Message, "\n")[0]
-		body := ""
-		if strings.Contains(c.Message, "\n") {
-			body = strings.TrimSpace(c.Message[strings.Index(c.Message, "\n"):])
+		hash := ref.Hash()
+		// For symbolic refs (like HEAD), resolve to actual hash
+		if ref.Type() == plumbing.SymbolicReference {
+			resolved, err := r.Reference(ref.Target(), true)
+			if err == nil {
+				hash = resolved.Hash()
+			}
 		}
 
-		refNames := refMap[c.Hash]
-		if refNames == nil {
-			refNames = []string{}
-		}
-		if parents == nil {
-			parents = []string{}
+		// If it's a tag, it might be an annotated tag.
+		// We need to resolve it to the commit hash it points to.
+		if ref.Name().IsTag() {
+			tagObj, err := r.TagObject(hash)
+			if err == nil {
+				// It's an annotated tag
+				commit, err := tagObj.Commit()
+				if err == nil {
+					hash = commit.Hash
+				}
+			}
+			// If r.TagObject fails, it's likely a lightweight tag,
+			// and hash already points to the commit.
 		}
 
-		commits = append(commits, GitCommit{
-			Hash:         c.Hash.String(),
-			AuthorName:   c.Author.Name,
-			AuthorEmail:  c.Author.Email,
-			Date:         c.Author.When,
-			Subject:      subject,
-			Body:         body,
-			ParentHashes: parents,
-			Refs:         refNames,
-		})
+		name := ref.Name().Short()
+		refMap[hash] = append(refMap[hash], name)
 		return nil
 	})
+	return refMap
+}
 
-	if err != nil && err != io.EOF {
-		return nil, err
+// newGitCommit converts a go-git commit into the GitCommit sent to the frontend.
+func newGitCommit(c *object.Commit, refMap map[plumbing.Hash][]string) GitCommit {
+	parents := []string{}
+	for _, ph := range c.ParentHashes {
+		parents = append(parents, ph.String())
 	}
 
-	return commits, nil
+	subject := strings.Split(c.Message, "\n")[0]
+	body := ""
+	if strings.Contains(c.Message, "\n") {
+		body = strings.TrimSpace(c.Message[strings.Index(c.Message, "\n"):])
+	}
+
+	refNames := refMap[c.Hash]
+	if refNames == nil {
+		refNames = []string{}
+	}
+
+	return GitCommit{
+		Hash:         c.Hash.String(),
+		AuthorName:   c.Author.Name,
+		AuthorEmail:  c.Author.Email,
+		Date:         c.Author.When,
+		Subject:      subject,
+		Body:         body,
+		ParentHashes: parents,
+		Refs:         refNames,
+		Notes:        []GitNote{},
+		Trailers:     parseTrailers(c.Message),
+		Signature:    SignatureInfo{Status: SignatureUnsigned},
+	}
 }
 
 func (a *App) GetCommitChanges(repoPath string, commitHash string) ([]CommitFileChange, error) {
@@ -786,9 +917,24 @@ func (a *App) GetCommitFileDiff(repoPath string, commitHash string, filePath str
 }
 
 func (a *App) Commit(repoPath string, subject string, body string, amend bool) error {
+	return a.CommitWithOptions(repoPath, CommitOptions{
+		Subject: subject,
+		Body:    body,
+		Amend:   amend,
+	})
+}
+
+func (a *App) CommitWithOptions(repoPath string, opts CommitOptions) (err error) {
+	// The hooks can run for long, so the commit can be cancelled
+	op := a.startOperation(OperationCommit, repoPath)
+	defer func() { err = a.finishOperation(op, err) }()
+
 	mu := getRepoMutex(repoPath)
 	mu.Lock()
 	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return err
+	}
 
 	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
 		Status:  "Committing changes...",
@@ -812,17 +958,123 @@ func (a *App) Commit(repoPath string, subject string, body string, amend bool) e
 		return err
 	}
 
-	msg := subject
-	if body != "" {
-		msg = subject + "\n\n" + body
+	msg := opts.Subject
+	if opts.Body != "" {
+		msg = opts.Subject + "\n\n" + opts.Body
+	}
+	if opts.FixupCommit != "" {
+		if opts.Amend {
+			err = errors.New("a fixup commit cannot amend HEAD")
+		} else {
+			msg, err = fixupMessage(r, opts.FixupCommit, opts.FixupKind, msg)
+		}
+		if err != nil {
+			runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+				Status:  fmt.Sprintf("Commit failed: %v", err),
+				Percent: -1,
+			})
+			return err
+		}
 	}
 
-	opts := &git.CommitOptions{
-		All:   false, // We only commit what is staged
-		Amend: amend,
+	author, committer, err := repoSignatures(repoPath)
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Commit failed: %v", err),
+			Percent: -1,
+		})
+		return err
+	}
+
+	if opts.Amend {
+		// Like git commit --amend, keep the authorship of the amended commit
+		if head, err := r.Head(); err == nil {
+			if c, err := r.CommitObject(head.Hash()); err == nil {
+				author = &c.Author
+			}
+		}
+	}
+	author, err = overrideAuthor(author, opts)
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Commit failed: %v", err),
+			Percent: -1,
+		})
+		return err
+	}
+
+	trailers := opts.Trailers
+	if opts.SignOff {
+		trailers = append(trailers, CommitTrailer{
+			Key:   "Signed-off-by",
+			Value: fmt.Sprintf("%s <%s>", committer.Name, committer.Email),
+		})
+	}
+	msg = appendTrailers(msg, trailers)
+
+	if len(opts.Paths) > 0 {
+		// Commit from an index limited to the paths, then put the full index
+		// back. The committed paths then match HEAD in the restored index.
+		idx, err := r.Storer.Index()
+		if err == nil {
+			var limited *index.Index
+			limited, err = limitIndexToPaths(r, idx, opts.Paths)
+			if err == nil {
+				err = r.Storer.SetIndex(limited)
+			}
+		}
+		if err != nil {
+			runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+				Status:  fmt.Sprintf("Commit failed: %v", err),
+				Percent: -1,
+			})
+			return err
+		}
+		defer func() { _ = r.Storer.SetIndex(idx) }()
+	}
+
+	if !opts.NoVerify {
+		err = a.runHook(op.ctx, repoPath, "pre-commit", nil, nil, commitHookEnv(repoPath)...)
+	}
+	if err == nil {
+		msg, err = a.runCommitMsgHooks(op.ctx, repoPath, msg, opts.NoVerify)
+	}
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Commit failed: %v", err),
+			Percent: -1,
+		})
+		return err
+	}
+
+	// The message rules are app settings rather than hooks, so NoVerify
+	// does not skip them. They apply to the message as the hooks left it.
+	commitMessagesMu.Lock()
+	settings, err := repoCommitMessageSettings(repoPath)
+	commitMessagesMu.Unlock()
+	if err == nil {
+		if violations := validateCommitMessage(settings.Rules, msg); len(violations) > 0 {
+			err := &CommitMessageError{Violations: violations}
+			runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+				Status:  "Commit failed: the commit message does not follow the repository's rules",
+				Percent: -1,
+			})
+			return err
+		}
+	}
+
+	commitOpts := &git.CommitOptions{
+		All:               false, // We only commit what is staged
+		AllowEmptyCommits: opts.AllowEmpty,
+		Amend:             opts.Amend,
+		Author:            author,
+		Committer:         committer,
 	}
 
-	_, err = w.Commit(msg, opts)
+	signing, err := repoSigningConfig(repoPath)
+	if err == nil && (opts.Sign || signing.SignCommit) {
+		commitOpts.Signer, err = signing.signer(op.ctx)
+	}
 	if err != nil {
 		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
 			Status:  fmt.Sprintf("Commit failed: %v", err),
@@ -831,6 +1083,18 @@ func (a *App) Commit(repoPath string, subject string, body string, amend bool) e
 		return err
 	}
 
+	_, err = w.Commit(msg, commitOpts)
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Commit failed: %v", err),
+			Percent: -1,
+		})
+		return err
+	}
+
+	// Like git, a failing post-commit hook does not affect the commit
+	_ = a.runHook(op.ctx, repoPath, "post-commit", nil, nil, commitHookEnv(repoPath)...)
+
 	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
 		Status:  "Commit completed",
 		Percent: 100,
@@ -839,6 +1103,9 @@ func (a *App) Commit(repoPath string, subject string, body string, amend bool) e
 	return nil
 }
 
+// Checkout switches to a local branch. For a remote-tracking branch such as
+// origin/main it checks out the local branch of the same name, creating it
+// with the remote branch as its upstream if it does not exist yet.
 func (a *App) Checkout(repoPath string, branchName string, isRemote bool) error {
 	mu := getRepoMutex(repoPath)
 	mu.Lock()
@@ -856,33 +1123,37 @@ func (a *App) Checkout(repoPath string, branchName string, isRemote bool) error
 
 	var branch plumbing.ReferenceName
 	if isRemote {
-		// If it's a remote branch, we usually want to create a local tracking branch
-		// Extract local branch name from the remote branch name (e.g., origin/main -> main)
-		parts := strings.Split(branchName, "/")
-		localName := branchName
-		if len(parts) > 1 {
-			localName = strings.Join(parts[1:], "/")
+		// Find the remote and remote branch the ref is fetched from; remote
+		// names may contain slashes, so the name alone is ambiguous
+		remoteRef, err := r.Reference(plumbing.ReferenceName("refs/remotes/"+branchName), true)
+		if err != nil {
+			return err
+		}
+		cfg, err := repoConfig(r)
+		if err != nil {
+			return err
+		}
+		remoteName, merge := remoteTrackingSource(cfg, remoteRef.Name())
+		if remoteName == "" {
+			return fmt.Errorf("%s is not fetched by any configured remote", branchName)
 		}
 
+		localName := merge.Short()
 		branch = plumbing.NewBranchReferenceName(localName)
 
 		// Check if a local branch already exists
 		_, err = r.Reference(branch, true)
 		if err != nil {
 			// Local branch does not exist, create it tracking the remote
-			// To create a local branch tracking a remote one with go-git:
-			// 1. Get the remote reference hash
-			remoteBranch := plumbing.NewRemoteReferenceName(parts[0], strings.Join(parts[1:], "/"))
-			remoteRef, err := r.Reference(remoteBranch, true)
-			if err != nil {
-				return err
-			}
-
-			return w.Checkout(&git.CheckoutOptions{
+			err = w.Checkout(&git.CheckoutOptions{
 				Branch: branch,
 				Create: true,
 				Hash:   remoteRef.Hash(),
 			})
+			if err != nil {
+				return err
+			}
+			return setupTracking(repoPath, r, localName, remoteRef.Name(), TrackAuto)
 		}
 	} else {
 		branch = plumbing.NewBranchReferenceName(branchName)
@@ -893,44 +1164,96 @@ func (a *App) Checkout(repoPath string, branchName string, isRemote bool) error
 	})
 }
 
+// CreateBranch creates a branch at HEAD.
 func (a *App) CreateBranch(repoPath string, name string, checkout bool) error {
+	return a.CreateBranchWithOptions(repoPath, BranchOptions{Name: name, Checkout: checkout})
+}
+
+// DeleteBranch deletes a local branch and its config. With deleteRemote it
+// also deletes the branch on a remote: remoteName, or if empty the branch's
+// upstream remote, else the default remote.
+func (a *App) DeleteBranch(repoPath string, branchName string, deleteRemote bool, remoteName string) (err error) {
+	// Only deleting the remote branch talks to the network
+	ctx := context.Background()
+	if deleteRemote {
+		op := a.startOperation(OperationDeleteBranch, repoPath)
+		defer func() { err = a.finishOperation(op, err) }()
+		ctx = op.ctx
+	}
+
 	mu := getRepoMutex(repoPath)
 	mu.Lock()
 	defer mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	r, err := git.PlainOpen(repoPath)
 	if err != nil {
 		return err
 	}
 
-	head, err := r.Head()
+	cfg, err := repoConfig(r)
 	if err != nil {
 		return err
 	}
+	remoteBranch := plumbing.NewBranchReferenceName(branchName)
+	if upstream, merge := upstreamOf(cfg, branchName); upstream != "" && (remoteName == "" || remoteName == upstream) {
+		remoteName = upstream
+		if merge != "" {
+			remoteBranch = merge
+		}
+	}
 
-	w, err := r.Worktree()
+	// Delete local branch
+	err = r.Storer.RemoveReference(plumbing.NewBranchReferenceName(branchName))
 	if err != nil {
 		return err
 	}
+	if _, ok := cfg.Branches[branchName]; ok {
+		delete(cfg.Branches, branchName)
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+	}
+
+	if deleteRemote {
+		remote, err := resolveRemote(repoPath, r, remoteName, true)
+		if err != nil {
+			return err
+		}
 
-	branchName := plumbing.NewBranchReferenceName(name)
+		url := remote.Config().URLs[0]
+		auth, _ := a.getAuth(ctx, repoPath, url)
 
-	// Create the branch
-	if checkout {
-		err = w.Checkout(&git.CheckoutOptions{
-			Branch: branchName,
-			Create: true,
-			Hash:   head.Hash(),
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Deleting %s on %s...", remoteBranch.Short(), remote.Config().Name),
+			Percent: -1,
+		})
+
+		// To delete a remote branch, we push an empty reference to it
+		refSpec := config.RefSpec(":" + remoteBranch.String())
+		err = a.withAuth(ctx, repoPath, url, auth, func(auth transport.AuthMethod) error {
+			return r.PushContext(ctx, &git.PushOptions{
+				RemoteName: remote.Config().Name,
+				RefSpecs:   []config.RefSpec{refSpec},
+				Auth:       auth,
+			})
+		})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return err
+		}
+
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  "Branch deletion completed",
+			Percent: 100,
 		})
-	} else {
-		ref := plumbing.NewHashReference(branchName, head.Hash())
-		err = r.Storer.SetReference(ref)
 	}
 
-	return err
+	return nil
 }
 
-func (a *App) DeleteBranch(repoPath string, branchName string, deleteRemote bool) error {
+func (a *App) CreateTag(repoPath string, name string, message string) error {
 	mu := getRepoMutex(repoPath)
 	mu.Lock()
 	defer mu.Unlock()
@@ -940,74 +1263,123 @@ func (a *App) DeleteBranch(repoPath string, branchName string, deleteRemote bool
 		return err
 	}
 
-	// Delete local branch
-	err = r.Storer.RemoveReference(plumbing.NewBranchReferenceName(branchName))
+	head, err := r.Head()
 	if err != nil {
 		return err
 	}
 
-	if deleteRemote {
-		// This is more complex as it requires authentication and pushing to the remote
-		// For now, let's try to delete it from 'origin'
-		remote, err := r.Remote("origin")
-		if err != nil {
-			return err
+	signing, err := repoSigningConfig(repoPath)
+	if err != nil {
+		return err
+	}
+
+	if message == "" {
+		// Like git, tag.gpgSign does not allow unsigned lightweight tags
+		if signing.SignTag {
+			return errors.New("tag signing is enabled (tag.gpgSign): a signed tag needs a message")
 		}
+		// Lightweight tag
+		_, err = r.CreateTag(name, head.Hash(), nil)
+		return err
+	}
 
-		auth, _ := a.getAuth(remote.Config().URLs[0])
+	// Annotated tag
+	// The tagger is the committer identity from the git config
+	_, tagger, err := repoSignatures(repoPath)
+	if err != nil {
+		return err
+	}
 
-		// To delete a remote branch, we push an empty reference to it
-		// RefSpec: refs/heads/branchName
-		refSpec := fmt.Sprintf(":refs/heads/%s", branchName)
-		err = r.Push(&git.PushOptions{
-			RemoteName: "origin",
-			RefSpecs:   []config.RefSpec{config.RefSpec(refSpec)},
-			Auth:       auth,
-		})
-		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+	if signing.SignTag {
+		signer, err := signing.signer(context.Background())
+		if err != nil {
 			return err
 		}
+		return createSignedTag(r, name, head.Hash(), message, tagger, signer)
 	}
 
-	return nil
+	_, err = r.CreateTag(name, head.Hash(), &git.CreateTagOptions{
+		Message: message,
+		Tagger:  tagger,
+	})
+	return err
 }
 
-func (a *App) CreateTag(repoPath string, name string, message string) error {
+// GetTags returns all tags, newest first, with their signatures verified.
+func (a *App) GetTags(repoPath string) ([]GitTag, error) {
 	mu := getRepoMutex(repoPath)
 	mu.Lock()
 	defer mu.Unlock()
 
 	r, err := git.PlainOpen(repoPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	head, err := r.Head()
+	v, err := newSignatureVerifier(repoPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	opts := &git.CreateTagOptions{
-		Message: message,
+	refs, err := r.Tags()
+	if err != nil {
+		return nil, err
 	}
 
-	if message != "" {
-		// Annotated tag
-		// We need a signature for annotated tags
-		opts.Tagger = &object.Signature{
-			Name:  "Celerix Git GUI",
-			Email: "gui@celerix.dev",
-			When:  time.Now(),
+	tags := []GitTag{}
+	var tagObjects []*object.Tag
+	var annotated []int
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tag := GitTag{
+			Name:       ref.Name().Short(),
+			Hash:       ref.Hash().String(),
+			CommitHash: ref.Hash().String(),
+			Signature:  SignatureInfo{Status: SignatureUnsigned},
+		}
+		if t, err := r.TagObject(ref.Hash()); err == nil {
+			tag.Annotated = true
+			tag.Message = strings.TrimSpace(t.Message)
+			tag.TaggerName = t.Tagger.Name
+			tag.TaggerEmail = t.Tagger.Email
+			tag.Date = t.Tagger.When
+			if c, err := t.Commit(); err == nil {
+				tag.CommitHash = c.Hash.String()
+			}
+			tagObjects = append(tagObjects, t)
+			annotated = append(annotated, len(tags))
+		} else if c, err := r.CommitObject(ref.Hash()); err == nil {
+			tag.Date = c.Committer.When
 		}
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = r.CreateTag(name, head.Hash(), opts)
-	return err
+	for i, info := range v.verifyTags(tagObjects) {
+		tags[annotated[i]].Signature = info
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Date.After(tags[j].Date)
+	})
+	return tags, nil
 }
 
-func (a *App) getAuth(remoteURL string) (ssh.AuthMethod, error) {
+// getAuth returns the auth for a remote. Over SSH it is the key of the
+// repository's identity profile if it has one, otherwise the default key in
+// ~/.ssh. Over HTTP(S) it is the stored username and password or token, if
+// any (see httpAuth).
+func (a *App) getAuth(ctx context.Context, repoPath string, remoteURL string) (transport.AuthMethod, error) {
+	if isHTTPURL(remoteURL) {
+		return a.httpAuth(ctx, repoPath, remoteURL)
+	}
 	if !strings.HasPrefix(remoteURL, "git@") && !strings.HasPrefix(remoteURL, "ssh://") {
-		return nil, nil // Use default (likely HTTP without auth or handled by git-agent)
+		return nil, nil // Use default (local paths, or handled by git-agent)
+	}
+
+	if profile, err := matchProfile(repoPath); err == nil && profile != nil && profile.SSHKeyPath != "" {
+		return ssh.NewPublicKeysFromFile("git", expandHome(profile.SSHKeyPath), "")
 	}
 
 	home, err := os.UserHomeDir()
@@ -1072,35 +1444,106 @@ func (p *gitProgressProxy) Write(data []byte) (n int, err error) {
 	return len(data), nil
 }
 
-func (a *App) Fetch(repoPath string) error {
+// FetchOptions controls a fetch. Remote defaults to the upstream remote of
+// the current branch, else origin; All fetches every remote instead. Prune
+// removes remote-tracking branches whose branch was deleted on the remote,
+// which fetch.prune and remote.<name>.prune also enable.
+type FetchOptions struct {
+	Remote string `json:"remote"`
+	All    bool   `json:"all"`
+	Prune  bool   `json:"prune"`
+}
+
+// Fetch fetches a remote, or the default remote (the upstream of the current
+// branch, else origin) if remoteName is empty.
+func (a *App) Fetch(repoPath string, remoteName string) error {
+	return a.FetchWithOptions(repoPath, FetchOptions{Remote: remoteName})
+}
+
+// FetchAll fetches every remote.
+func (a *App) FetchAll(repoPath string) error {
+	return a.FetchWithOptions(repoPath, FetchOptions{All: true})
+}
+
+// FetchWithOptions fetches one or all remotes. When fetching all, a failing
+// remote does not stop the others; the failures are returned together.
+func (a *App) FetchWithOptions(repoPath string, opts FetchOptions) (err error) {
+	op := a.startOperation(OperationFetch, repoPath)
+	defer func() { err = a.finishOperation(op, err) }()
+
 	mu := getRepoMutex(repoPath)
 	mu.Lock()
 	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return err
+	}
 
 	r, err := git.PlainOpen(repoPath)
 	if err != nil {
 		return err
 	}
 
-	remote, err := r.Remote("origin")
-	if err != nil {
-		return err
+	names := []string{opts.Remote}
+	if opts.All {
+		if names, err = remoteNames(r); err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return errors.New("no remote repository is configured")
+		}
 	}
 
-	auth, _ := a.getAuth(remote.Config().URLs[0])
+	var errs []error
+	for _, name := range names {
+		remote, err := resolveRemote(repoPath, r, name, false)
+		if err == nil {
+			err = a.fetchRemote(op, r, repoPath, remote, opts.Prune)
+		}
+		if err != nil {
+			if !opts.All || op.ctx.Err() != nil {
+				return err
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
 
-	progress := &gitProgressProxy{a: a, status: "Fetching origin..."}
 	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
-		Status:  "Fetching origin...",
+		Status:  "Fetch completed",
+		Percent: 100,
+	})
+
+	return nil
+}
+
+// fetchRemote fetches the branches and notes of a remote, pruning stale
+// remote-tracking branches when prune is set or configured. Notes that cannot
+// be fetched are a warning of the operation, the branches are fetched anyway.
+func (a *App) fetchRemote(op *runningOperation, r *git.Repository, repoPath string, remote *git.Remote, prune bool) error {
+	ctx := op.ctx
+	name := remote.Config().Name
+	url := remote.Config().URLs[0]
+	auth, _ := a.getAuth(ctx, repoPath, url)
+
+	status := fmt.Sprintf("Fetching %s...", name)
+	progress := &gitProgressProxy{a: a, status: status}
+	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+		Status:  status,
 		Percent: 0,
 	})
 
-	err = r.Fetch(&git.FetchOptions{
-		RemoteName: "origin",
-		Auth:       auth,
-		Progress:   progress,
+	err := a.withAuth(ctx, repoPath, url, auth, func(auth transport.AuthMethod) error {
+		return r.FetchContext(ctx, &git.FetchOptions{
+			RemoteName: name,
+			Auth:       auth,
+			Progress:   progress,
+		})
 	})
-	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+	// Like git, fetching an empty repository is not an error
+	empty := errors.Is(err, transport.ErrEmptyRemoteRepository)
+	if err != nil && !empty && !errors.Is(err, git.NoErrAlreadyUpToDate) {
 		// Emit error status if it failed
 		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
 			Status:  fmt.Sprintf("Fetch failed: %v", err),
@@ -1109,99 +1552,181 @@ func (a *App) Fetch(repoPath string) error {
 		return err
 	}
 
-	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
-		Status:  "Fetch completed",
-		Percent: 100,
-	})
+	if prune || pruneConfigured(repoPath, name) {
+		if err := a.pruneRemote(ctx, r, repoPath, remote); err != nil {
+			runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+				Status:  fmt.Sprintf("Fetch failed: %v", err),
+				Percent: -1,
+			})
+			return err
+		}
+	}
+	if empty {
+		return nil
+	}
 
+	// The credentials may have been entered during the fetch
+	auth, _ = a.getAuth(ctx, repoPath, url)
+	if err := a.fetchNotes(ctx, r, repoPath, name, url, auth); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		op.warn("The notes of %s were not fetched: %v", name, err)
+	}
 	return nil
 }
 
-func (a *App) Pull(repoPath string) error {
+// Pull fetches a remote and integrates the upstream of the current branch
+// as configured (see PullWithOptions).
+func (a *App) Pull(repoPath string, remoteName string) error {
+	return a.PullWithOptions(repoPath, PullOptions{Remote: remoteName})
+}
+
+// PushOptions controls a push. Remote defaults to the push remote of the
+// current branch (see defaultRemote). NoVerify skips the pre-push hook.
+//
+// Branch pushes a single local branch to RemoteBranch, which defaults to the
+// branch's upstream on that remote or else the same name; SetUpstream then
+// makes the pushed branch the upstream. Tags and AllTags push tags. Without
+// a branch or tags the configured push refspecs are used.
+//
+// ForceWithLease only overwrites a remote ref while it still has the value of
+// its remote-tracking ref, or ExpectedHash for Branch. Options are
+// "key=value" push options for the server.
+type PushOptions struct {
+	Remote         string   `json:"remote"`
+	NoVerify       bool     `json:"noVerify"`
+	Branch         string   `json:"branch"`
+	RemoteBranch   string   `json:"remoteBranch"`
+	SetUpstream    bool     `json:"setUpstream"`
+	Force          bool     `json:"force"`
+	ForceWithLease bool     `json:"forceWithLease"`
+	ExpectedHash   string   `json:"expectedHash"`
+	Tags           []string `json:"tags"`
+	AllTags        bool     `json:"allTags"`
+	Atomic         bool     `json:"atomic"`
+	Options        []string `json:"options"`
+}
+
+func (a *App) Push(repoPath string, remoteName string) error {
+	return a.PushWithOptions(repoPath, PushOptions{Remote: remoteName})
+}
+
+// PushWithOptions pushes to every push URL of a remote. Each push is planned
+// against the refs the remote advertises first (see PreflightPush) and
+// refused if any ref would be rejected; the pre-push hook runs on the plan
+// unless NoVerify is set.
+func (a *App) PushWithOptions(repoPath string, opts PushOptions) (err error) {
+	op := a.startOperation(OperationPush, repoPath)
+	defer func() { err = a.finishOperation(op, err) }()
+
 	mu := getRepoMutex(repoPath)
 	mu.Lock()
 	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return err
+	}
 
 	r, err := git.PlainOpen(repoPath)
 	if err != nil {
 		return err
 	}
 
-	w, err := r.Worktree()
+	remote, err := resolveRemote(repoPath, r, opts.Remote, true)
 	if err != nil {
 		return err
 	}
+	name := remote.Config().Name
 
-	remote, err := r.Remote("origin")
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+	refSpecs, branchDst, err := pushSpecs(r, cfg, remote, &opts)
 	if err != nil {
 		return err
 	}
 
-	auth, _ := a.getAuth(remote.Config().URLs[0])
-
-	progress := &gitProgressProxy{a: a, status: "Pulling origin..."}
-	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
-		Status:  "Pulling origin...",
-		Percent: 0,
-	})
-
-	err = w.Pull(&git.PullOptions{
-		RemoteName: "origin",
-		Auth:       auth,
-		Progress:   progress,
-	})
-	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
-		// Emit error status if it failed
+	fail := func(err error) error {
 		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
-			Status:  fmt.Sprintf("Pull failed: %v", err),
+			Status:  fmt.Sprintf("Push failed: %v", err),
 			Percent: -1,
 		})
 		return err
 	}
 
-	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
-		Status:  "Pull completed",
-		Percent: 100,
-	})
-
-	return nil
-}
+	// Like git, push to every push URL of the remote
+	for _, url := range pushURLs(cfg, remote) {
+		remoteRefs, err := a.lsRemote(op.ctx, repoPath, url)
+		if err != nil {
+			return fail(err)
+		}
+		updates, err := planPush(repoPath, r, cfg, name, refSpecs, branchDst, remoteRefs, opts)
+		if err != nil {
+			return fail(err)
+		}
+		if err := pushRejection(updates); err != nil {
+			return fail(err)
+		}
 
-func (a *App) Push(repoPath string) error {
-	mu := getRepoMutex(repoPath)
-	mu.Lock()
-	defer mu.Unlock()
+		if !opts.NoVerify && len(updates) > 0 {
+			err := a.runHook(op.ctx, repoPath, "pre-push", []string{name, url}, strings.NewReader(prePushInput(updates)))
+			if err != nil {
+				return fail(err)
+			}
+		}
 
-	r, err := git.PlainOpen(repoPath)
-	if err != nil {
-		return err
-	}
+		// Reading the refs may have asked for credentials
+		auth, _ := a.getAuth(op.ctx, repoPath, url)
 
-	remote, err := r.Remote("origin")
-	if err != nil {
-		return err
-	}
+		status := fmt.Sprintf("Pushing to %s...", name)
+		progress := &gitProgressProxy{a: a, status: status}
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  status,
+			Percent: 0,
+		})
 
-	auth, _ := a.getAuth(remote.Config().URLs[0])
+		if specs := exactPushSpecs(updates); len(specs) > 0 {
+			pushOpts := &git.PushOptions{
+				RemoteName: name,
+				RemoteURL:  url,
+				RefSpecs:   specs,
+				Auth:       auth,
+				Progress:   progress,
+				Atomic:     opts.Atomic,
+				Options:    pushOptionsMap(opts.Options),
+			}
+			if opts.ForceWithLease {
+				pushOpts.RequireRemoteRefs = pushLeases(updates)
+			}
+			err = a.withAuth(op.ctx, repoPath, url, auth, func(auth transport.AuthMethod) error {
+				pushOpts.Auth = auth
+				return r.PushContext(op.ctx, pushOpts)
+			})
+			if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+				return fail(err)
+			}
+		}
 
-	progress := &gitProgressProxy{a: a, status: "Pushing to origin..."}
-	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
-		Status:  "Pushing to origin...",
-		Percent: 0,
-	})
+		// Notes go along with the branches, but never make the push fail
+		auth, _ = a.getAuth(op.ctx, repoPath, url)
+		if err := a.pushNotes(op.ctx, r, repoPath, name, url, auth); err != nil {
+			if op.ctx.Err() != nil {
+				return fail(err)
+			}
+			op.warn("The notes were not pushed to %s: %v", name, err)
+		}
+	}
 
-	err = r.Push(&git.PushOptions{
-		RemoteName: "origin",
-		Auth:       auth,
-		Progress:   progress,
-	})
-	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
-		// Emit error status if it failed
-		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
-			Status:  fmt.Sprintf("Push failed: %v", err),
-			Percent: -1,
-		})
-		return err
+	if opts.SetUpstream {
+		cfg, err := repoConfig(r)
+		if err != nil {
+			return fail(err)
+		}
+		setBranchUpstream(cfg, opts.Branch, name, branchDst)
+		if err := r.SetConfig(cfg); err != nil {
+			return fail(err)
+		}
 	}
 
 	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
@@ -1290,12 +1815,15 @@ func (a *App) generateSimpleDiff(old, new string) string {
 	return result.String()
 }
 
-func (a *App) dirSize(path string) (int64, error) {
+func (a *App) dirSize(ctx context.Context, path string) (int64, error) {
 	var size int64
 	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if !info.IsDir() {
 			size += info.Size()
 		}
@@ -0,0 +1,613 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// Config scopes, from lowest to highest precedence.
+const (
+	ScopeSystem = "system"
+	ScopeGlobal = "global"
+	ScopeLocal  = "local"
+)
+
+// maxIncludeDepth guards against include cycles, like git's own limit.
+const maxIncludeDepth = 10
+
+// configEntry is a single key/value read from a git config file. Section and
+// Name are lower-cased, Subsection keeps its case like in git.
+type configEntry struct {
+	Section    string
+	Subsection string
+	Name       string
+	Value      string
+	File       string
+	Scope      string
+
+	// start and end are the byte offsets of the entry within File.
+	start, end int
+}
+
+// configSection is one occurrence of a section header in a config file. end is
+// the offset after the header or the last entry that follows it.
+type configSection struct {
+	Section    string
+	Subsection string
+	start, end int
+}
+
+// Key returns the entry in git's dotted notation, e.g. remote.origin.url.
+func (e configEntry) Key() string {
+	if e.Subsection != "" {
+		return e.Section + "." + e.Subsection + "." + e.Name
+	}
+	return e.Section + "." + e.Name
+}
+
+// gitConfig is the merged view of all config files of a repository, in the
+// order git reads them. Later entries override earlier ones.
+type gitConfig struct {
+	Entries []configEntry
+}
+
+// Get returns the effective (last) value of a key.
+func (c *gitConfig) Get(key string) (string, bool) {
+	e := c.entry(key)
+	if e == nil {
+		return "", false
+	}
+	return e.Value, true
+}
+
+// GetBool returns the effective value of a boolean key, or def if unset.
+func (c *gitConfig) GetBool(key string, def bool) bool {
+	v, ok := c.Get(key)
+	if !ok {
+		return def
+	}
+	return parseConfigBool(v)
+}
+
+// GetAll returns all values of a multi-valued key in order.
+func (c *gitConfig) GetAll(key string) []string {
+	var values []string
+	section, subsection, name := splitConfigKey(key)
+	for _, e := range c.Entries {
+		if e.Section == section && e.Subsection == subsection && e.Name == name {
+			values = append(values, e.Value)
+		}
+	}
+	return values
+}
+
+func (c *gitConfig) entry(key string) *configEntry {
+	section, subsection, name := splitConfigKey(key)
+	for i := len(c.Entries) - 1; i >= 0; i-- {
+		e := &c.Entries[i]
+		if e.Section == section && e.Subsection == subsection && e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// parseConfigBool interprets a git boolean: a key without value is true, as
+// are yes/on/true and non-zero numbers.
+func parseConfigBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "":
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n != 0
+}
+
+// splitConfigKey splits section.subsection.name, lower-casing the parts that
+// are case-insensitive in git.
+func splitConfigKey(key string) (section, subsection, name string) {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first < 0 {
+		return strings.ToLower(key), "", ""
+	}
+	section = strings.ToLower(key[:first])
+	name = strings.ToLower(key[last+1:])
+	if first != last {
+		subsection = key[first+1 : last]
+	}
+	return section, subsection, name
+}
+
+// configFiles returns the config files of each scope in the order git reads
+// them. Files that do not exist are included; readers skip them.
+func configFiles(scope string, gitDir string) []string {
+	switch scope {
+	case ScopeSystem:
+		if os.Getenv("GIT_CONFIG_NOSYSTEM") != "" {
+			return nil
+		}
+		if p := os.Getenv("GIT_CONFIG_SYSTEM"); p != "" {
+			return []string{p}
+		}
+		if goruntime.GOOS == "windows" {
+			return []string{filepath.Join(os.Getenv("ProgramFiles"), "Git", "etc", "gitconfig")}
+		}
+		return []string{"/etc/gitconfig"}
+	case ScopeGlobal:
+		if p := os.Getenv("GIT_CONFIG_GLOBAL"); p != "" {
+			return []string{p}
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		xdg := os.Getenv("XDG_CONFIG_HOME")
+		if xdg == "" {
+			xdg = filepath.Join(home, ".config")
+		}
+		return []string{filepath.Join(xdg, "git", "config"), filepath.Join(home, ".gitconfig")}
+	case ScopeLocal:
+		if gitDir == "" {
+			return nil
+		}
+		return []string{filepath.Join(commonGitDir(gitDir), "config")}
+	}
+	return nil
+}
+
+// writableConfigFile returns the file git writes to for a scope: the local
+// repository config, ~/.gitconfig (unless only the XDG file exists) or the
+// system config.
+func writableConfigFile(scope string, gitDir string) (string, error) {
+	files := configFiles(scope, gitDir)
+	if len(files) == 0 {
+		return "", fmt.Errorf("no config file for scope %s", scope)
+	}
+	if scope == ScopeGlobal && len(files) == 2 {
+		if _, err := os.Stat(files[1]); err != nil {
+			if _, err := os.Stat(files[0]); err == nil {
+				return files[0], nil
+			}
+		}
+		return files[1], nil
+	}
+	return files[len(files)-1], nil
+}
+
+// findGitDir returns the git directory of a repository: the .git directory,
+// the target of a .git file (worktrees, submodules) or the path itself for a
+// bare repository.
+func findGitDir(repoPath string) (string, error) {
+	dotGit := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(dotGit)
+	if err == nil && info.IsDir() {
+		return dotGit, nil
+	}
+	if err == nil {
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return "", err
+		}
+		line := strings.TrimSpace(string(data))
+		if !strings.HasPrefix(line, "gitdir:") {
+			return "", fmt.Errorf("invalid .git file in %s", repoPath)
+		}
+		dir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(repoPath, dir)
+		}
+		return filepath.Clean(dir), nil
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "HEAD")); err == nil {
+		return repoPath, nil
+	}
+	return "", git.ErrRepositoryNotExists
+}
+
+// commonGitDir returns the directory a git dir shares with the other
+// worktrees of its repository, which holds the config and hooks. For a linked
+// worktree that is the main git dir named by its commondir file, for any
+// other repository the git dir itself.
+func commonGitDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(data))
+	if dir == "" {
+		return gitDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// includeContext carries what conditional includes are evaluated against.
+type includeContext struct {
+	gitDir     string
+	branch     string
+	remoteURLs []string
+}
+
+// loadGitConfig reads the system, global and local config of a repository,
+// following include and includeIf directives. An empty repoPath only reads
+// the system and global config.
+func loadGitConfig(repoPath string) (*gitConfig, error) {
+	ctx := &includeContext{}
+	if repoPath != "" {
+		gitDir, err := findGitDir(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		ctx.gitDir = gitDir
+		if head, err := os.ReadFile(filepath.Join(gitDir, "HEAD")); err == nil {
+			ctx.branch = strings.TrimPrefix(strings.TrimSpace(string(head)), "ref: refs/heads/")
+		}
+		if r, err := git.PlainOpen(repoPath); err == nil {
+			if remotes, err := r.Remotes(); err == nil {
+				for _, remote := range remotes {
+					ctx.remoteURLs = append(ctx.remoteURLs, remote.Config().URLs...)
+				}
+			}
+		}
+	}
+
+	cfg := &gitConfig{}
+	for _, scope := range []string{ScopeSystem, ScopeGlobal, ScopeLocal} {
+		for _, file := range configFiles(scope, ctx.gitDir) {
+			entries, err := readConfigFile(file, scope, ctx, 0)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Entries = append(cfg.Entries, entries...)
+		}
+	}
+	return cfg, nil
+}
+
+// readConfigFile parses a config file and the files it includes. A missing
+// file yields no entries.
+func readConfigFile(path string, scope string, ctx *includeContext, depth int) ([]configEntry, error) {
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("exceeded maximum include depth while including %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, _, err := parseConfig(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var result []configEntry
+	for _, e := range parsed {
+		e.File = path
+		e.Scope = scope
+		result = append(result, e)
+
+		if e.Name != "path" || e.Value == "" {
+			continue
+		}
+		include := false
+		switch e.Section {
+		case "include":
+			include = e.Subsection == ""
+		case "includeif":
+			include = ctx.matches(e.Subsection, path)
+		}
+		if !include {
+			continue
+		}
+
+		included, err := readConfigFile(resolveConfigPath(e.Value, path), scope, ctx, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, included...)
+	}
+	return result, nil
+}
+
+// resolveConfigPath expands ~/ and resolves paths relative to the directory of
+// the config file that references them.
+func resolveConfigPath(p string, relativeTo string) string {
+	if strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[2:])
+		}
+	}
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(relativeTo), p)
+}
+
+// matches evaluates an includeIf condition (gitdir:, gitdir/i:, onbranch: and
+// hasconfig:remote.*.url:).
+func (ctx *includeContext) matches(condition string, configPath string) bool {
+	switch {
+	case strings.HasPrefix(condition, "gitdir:"):
+		return ctx.gitDir != "" && matchGitDir(strings.TrimPrefix(condition, "gitdir:"), ctx.gitDir, configPath, false)
+	case strings.HasPrefix(condition, "gitdir/i:"):
+		return ctx.gitDir != "" && matchGitDir(strings.TrimPrefix(condition, "gitdir/i:"), ctx.gitDir, configPath, true)
+	case strings.HasPrefix(condition, "onbranch:"):
+		pattern := strings.TrimPrefix(condition, "onbranch:")
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		return ctx.branch != "" && globMatch(pattern, ctx.branch, false)
+	case strings.HasPrefix(condition, "hasconfig:remote.*.url:"):
+		pattern := strings.TrimPrefix(condition, "hasconfig:remote.*.url:")
+		for _, u := range ctx.remoteURLs {
+			if globMatch(pattern, u, false) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchGitDir(pattern string, gitDir string, configPath string, foldCase bool) bool {
+	// A trailing slash matches everything below the directory.
+	prefix := strings.HasSuffix(pattern, "/")
+
+	switch {
+	case strings.HasPrefix(pattern, "~/"):
+		pattern = resolveConfigPath(pattern, configPath)
+	case strings.HasPrefix(pattern, "./"):
+		pattern = filepath.Join(filepath.Dir(configPath), pattern[2:])
+	case !filepath.IsAbs(pattern) && !strings.HasPrefix(pattern, "/"):
+		pattern = "**/" + pattern
+	}
+	pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+	if prefix {
+		pattern += "/**"
+	}
+
+	dir := filepath.ToSlash(gitDir)
+	if globMatch(pattern, dir, foldCase) {
+		return true
+	}
+	// Like git, also match against the path with symlinks resolved.
+	if resolved, err := filepath.EvalSymlinks(gitDir); err == nil {
+		return globMatch(pattern, filepath.ToSlash(resolved), foldCase)
+	}
+	return false
+}
+
+// globMatch matches wildmatch-style patterns where ** spans directories and *
+// does not.
+func globMatch(pattern string, s string, foldCase bool) bool {
+	var b strings.Builder
+	if foldCase {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+				b.WriteString("(?:.*/)?")
+			} else {
+				b.WriteString(".*")
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+// parseConfig parses the git config file format: sections, subsections,
+// quoted values with escapes, comments and line continuations. Keys without
+// a value are implicitly "true". Entries and sections carry their offsets so
+// the file can be edited in place.
+func parseConfig(data string) ([]configEntry, []configSection, error) {
+	var entries []configEntry
+	var sections []configSection
+	var section, subsection string
+	line := 1
+
+	i := 0
+	for i < len(data) {
+		c := data[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || c == ';':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case c == '[':
+			start := i
+			end := strings.IndexByte(data[i:], ']')
+			if end < 0 {
+				return nil, nil, fmt.Errorf("line %d: unterminated section header", line)
+			}
+			header := data[i+1 : i+end]
+			i += end + 1
+
+			var err error
+			section, subsection, err = parseSectionHeader(header)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			sections = append(sections, configSection{
+				Section:    section,
+				Subsection: subsection,
+				start:      start,
+				end:        i,
+			})
+		case isConfigKeyChar(c):
+			if section == "" {
+				return nil, nil, fmt.Errorf("line %d: key outside of a section", line)
+			}
+			start := i
+			for i < len(data) && isConfigKeyChar(data[i]) {
+				i++
+			}
+			name := strings.ToLower(data[start:i])
+			for i < len(data) && (data[i] == ' ' || data[i] == '\t') {
+				i++
+			}
+
+			value := "true"
+			if i < len(data) && data[i] == '=' {
+				var lines int
+				var err error
+				value, i, lines, err = parseConfigValue(data, i+1)
+				if err != nil {
+					return nil, nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				line += lines
+			}
+
+			entries = append(entries, configEntry{
+				Section:    section,
+				Subsection: subsection,
+				Name:       name,
+				Value:      value,
+				start:      start,
+				end:        i,
+			})
+			sections[len(sections)-1].end = i
+		default:
+			return nil, nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	return entries, sections, nil
+}
+
+func parseSectionHeader(header string) (section, subsection string, err error) {
+	header = strings.TrimSpace(header)
+	if idx := strings.IndexAny(header, " \t"); idx >= 0 {
+		section = strings.ToLower(header[:idx])
+		rest := strings.TrimSpace(header[idx:])
+		if len(rest) < 2 || rest[0] != '"' || rest[len(rest)-1] != '"' {
+			return "", "", fmt.Errorf("invalid subsection in [%s]", header)
+		}
+		var b strings.Builder
+		for j := 1; j < len(rest)-1; j++ {
+			if rest[j] == '\\' && j+1 < len(rest)-1 {
+				j++
+			}
+			b.WriteByte(rest[j])
+		}
+		return section, b.String(), nil
+	}
+	// Deprecated [section.subsection] syntax, subsection is lower-cased.
+	if idx := strings.Index(header, "."); idx >= 0 {
+		return strings.ToLower(header[:idx]), strings.ToLower(header[idx+1:]), nil
+	}
+	return strings.ToLower(header), "", nil
+}
+
+// parseConfigValue parses a value starting after the '=' and returns it with
+// the position after the value and the number of newlines consumed.
+func parseConfigValue(data string, i int) (string, int, int, error) {
+	var b strings.Builder
+	quoted := false
+	lines := 0
+	// pending holds unquoted whitespace that is only kept if more value follows.
+	pending := ""
+
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t') {
+		i++
+	}
+
+	for i < len(data) {
+		c := data[i]
+		switch {
+		case c == '\n' && !quoted:
+			return b.String(), i, lines, nil
+		case c == '\n':
+			return "", i, lines, errors.New("unterminated quoted value")
+		case (c == '#' || c == ';') && !quoted:
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			return b.String(), i, lines, nil
+		case c == '"':
+			b.WriteString(pending)
+			pending = ""
+			quoted = !quoted
+			i++
+		case c == '\\':
+			if i+1 >= len(data) {
+				return "", i, lines, errors.New("trailing backslash")
+			}
+			b.WriteString(pending)
+			pending = ""
+			next := data[i+1]
+			switch next {
+			case '\n':
+				lines++
+			case '\r':
+				if i+2 < len(data) && data[i+2] == '\n' {
+					i++
+					lines++
+				}
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case '"', '\\':
+				b.WriteByte(next)
+			default:
+				return "", i, lines, fmt.Errorf("invalid escape \\%c", next)
+			}
+			i += 2
+		case (c == ' ' || c == '\t' || c == '\r') && !quoted:
+			// Like git, unquoted whitespace is kept as plain spaces.
+			pending += " "
+			i++
+		default:
+			b.WriteString(pending)
+			pending = ""
+			b.WriteByte(c)
+			i++
+		}
+	}
+	if quoted {
+		return "", i, lines, errors.New("unterminated quoted value")
+	}
+	return b.String(), i, lines, nil
+}
+
+func isConfigKeyChar(c byte) bool {
+	return c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,252 @@
+package backend
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GraphOptions controls which commits are included in a commit graph page.
+type GraphOptions struct {
+	Skip                 int  `json:"skip"`
+	Count                int  `json:"count"`
+	FirstParent          bool `json:"firstParent"`
+	SimplifyByDecoration bool `json:"simplifyByDecoration"`
+}
+
+// GraphEdge is a line segment drawn in a single graph row.
+// Kind is one of:
+//   - "through": a lane passing the row from top to bottom
+//   - "incoming": from the top of the row (FromLane) into the commit node (ToLane)
+//   - "outgoing": from the commit node (FromLane) to the bottom of the row (ToLane)
+type GraphEdge struct {
+	FromLane int    `json:"fromLane"`
+	ToLane   int    `json:"toLane"`
+	Color    int    `json:"color"`
+	Kind     string `json:"kind"`
+}
+
+type GraphRow struct {
+	Commit    GitCommit   `json:"commit"`
+	Lane      int         `json:"lane"`
+	Color     int         `json:"color"`
+	LaneCount int         `json:"laneCount"`
+	Edges     []GraphEdge `json:"edges"`
+}
+
+type CommitGraph struct {
+	Rows      []GraphRow `json:"rows"`
+	Total     int        `json:"total"`
+	HasMore   bool       `json:"hasMore"`
+	LaneCount int        `json:"laneCount"`
+}
+
+// GetCommitGraph returns a page of the commit history together with its graph
+// layout. The layout is always computed from the newest commit, so lanes and
+// colours of a page line up with the pages loaded before it.
+func (a *App) GetCommitGraph(repoPath string, opts GraphOptions) (*CommitGraph, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered, parents := idx.topoOrder(opts.FirstParent)
+	if opts.SimplifyByDecoration {
+		ordered, parents = simplifyByDecoration(ordered, parents, idx.Refs)
+	}
+
+	end := len(ordered)
+	if opts.Count > 0 && opts.Skip+opts.Count < end {
+		end = opts.Skip + opts.Count
+	}
+
+	result := &CommitGraph{
+		Rows:    []GraphRow{},
+		Total:   len(ordered),
+		HasMore: end < len(ordered),
+	}
+
+	notes := readAllNotes(r)
+	protected := protectedCommits(repoPath, r, idx)
+	var objects []*object.Commit
+	layout := newGraphLayout()
+	for i := 0; i < end; i++ {
+		h := ordered[i]
+		row := layout.place(h, parents[h])
+		if i < opts.Skip {
+			continue
+		}
+
+		c, err := r.CommitObject(h)
+		if err != nil {
+			return nil, err
+		}
+		row.Commit = newGitCommit(c, idx.Refs)
+		if n, ok := notes[h]; ok {
+			row.Commit.Notes = n
+		}
+		row.Commit.OnProtectedBranch = protected[h]
+		if row.LaneCount > result.LaneCount {
+			result.LaneCount = row.LaneCount
+		}
+		result.Rows = append(result.Rows, row)
+		objects = append(objects, c)
+	}
+
+	verifier, err := newSignatureVerifier(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range verifier.verifyCommits(objects) {
+		result.Rows[i].Commit.Signature = info
+	}
+
+	return result, nil
+}
+
+// simplifyByDecoration keeps only commits that carry a reference or are root
+// commits, rewriting parents to the nearest kept ancestors.
+func simplifyByDecoration(ordered []plumbing.Hash, parents map[plumbing.Hash][]plumbing.Hash, refMap map[plumbing.Hash][]string) ([]plumbing.Hash, map[plumbing.Hash][]plumbing.Hash) {
+	keep := func(h plumbing.Hash) bool {
+		return len(refMap[h]) > 0 || len(parents[h]) == 0
+	}
+
+	// nearest holds, for each commit, the kept commits that represent it.
+	// Walking oldest-first guarantees parents are resolved before children.
+	nearest := make(map[plumbing.Hash][]plumbing.Hash, len(ordered))
+	rewritten := make(map[plumbing.Hash][]plumbing.Hash)
+	for i := len(ordered) - 1; i >= 0; i-- {
+		h := ordered[i]
+
+		var ancestors []plumbing.Hash
+		seen := make(map[plumbing.Hash]bool)
+		for _, p := range parents[h] {
+			for _, k := range nearest[p] {
+				if !seen[k] {
+					seen[k] = true
+					ancestors = append(ancestors, k)
+				}
+			}
+		}
+
+		if keep(h) {
+			rewritten[h] = ancestors
+			nearest[h] = []plumbing.Hash{h}
+		} else {
+			nearest[h] = ancestors
+		}
+	}
+
+	var kept []plumbing.Hash
+	for _, h := range ordered {
+		if keep(h) {
+			kept = append(kept, h)
+		}
+	}
+	return kept, rewritten
+}
+
+// graphLayout assigns commits to lanes row by row. A lane holds the hash of
+// the commit it is waiting for; a zero hash marks a free lane.
+type graphLayout struct {
+	lanes     []plumbing.Hash
+	colors    []int
+	nextColor int
+}
+
+func newGraphLayout() *graphLayout {
+	return &graphLayout{}
+}
+
+func (l *graphLayout) place(hash plumbing.Hash, parents []plumbing.Hash) GraphRow {
+	row := GraphRow{Edges: []GraphEdge{}}
+
+	// Lanes of children waiting for this commit converge into the leftmost one.
+	var incoming []int
+	for i, h := range l.lanes {
+		if h == hash {
+			incoming = append(incoming, i)
+		}
+	}
+
+	if len(incoming) == 0 {
+		row.Lane = l.allocate()
+		l.colors[row.Lane] = l.newColor()
+	} else {
+		row.Lane = incoming[0]
+	}
+	row.Color = l.colors[row.Lane]
+
+	for _, i := range incoming {
+		row.Edges = append(row.Edges, GraphEdge{FromLane: i, ToLane: row.Lane, Color: l.colors[i], Kind: "incoming"})
+		l.lanes[i] = plumbing.ZeroHash
+	}
+	for i, h := range l.lanes {
+		if !h.IsZero() {
+			row.Edges = append(row.Edges, GraphEdge{FromLane: i, ToLane: i, Color: l.colors[i], Kind: "through"})
+		}
+	}
+
+	// Reserve the commit lane so merge parents are not allocated on top of it.
+	l.lanes[row.Lane] = hash
+	for i, p := range parents {
+		target := l.find(p)
+		switch {
+		case target >= 0:
+		case i == 0:
+			target = row.Lane
+			l.colors[target] = row.Color
+		default:
+			target = l.allocate()
+			l.colors[target] = l.newColor()
+		}
+		l.lanes[target] = p
+		row.Edges = append(row.Edges, GraphEdge{FromLane: row.Lane, ToLane: target, Color: l.colors[target], Kind: "outgoing"})
+	}
+	if l.lanes[row.Lane] == hash {
+		l.lanes[row.Lane] = plumbing.ZeroHash
+	}
+
+	row.LaneCount = len(l.lanes)
+	for len(l.lanes) > 0 && l.lanes[len(l.lanes)-1].IsZero() {
+		l.lanes = l.lanes[:len(l.lanes)-1]
+		l.colors = l.colors[:len(l.colors)-1]
+	}
+
+	return row
+}
+
+func (l *graphLayout) find(hash plumbing.Hash) int {
+	for i, h := range l.lanes {
+		if h == hash {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *graphLayout) allocate() int {
+	for i, h := range l.lanes {
+		if h.IsZero() {
+			return i
+		}
+	}
+	l.lanes = append(l.lanes, plumbing.ZeroHash)
+	l.colors = append(l.colors, 0)
+	return len(l.lanes) - 1
+}
+
+func (l *graphLayout) newColor() int {
+	c := l.nextColor
+	l.nextColor++
+	return c
+}
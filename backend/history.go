@@ -0,0 +1,564 @@
+package backend
+
+import (
+	"container/heap"
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraph "github.com/go-git/go-git/v5/plumbing/format/commitgraph/v2"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// historyIndexVersion is bumped whenever the on-disk format changes so stale
+// cache files are rebuilt instead of decoded.
+const historyIndexVersion = 1
+
+var historyIndexes sync.Map
+
+type indexedCommit struct {
+	Parents    []plumbing.Hash
+	Generation uint64
+	When       int64
+}
+
+// historyIndex is a compact copy of the commit graph of a repository. It is
+// keyed by a fingerprint of all references, so it only has to be updated when
+// a ref moves, and then only for the commits that are new since.
+type historyIndex struct {
+	Version     int
+	Fingerprint string
+	Tips        []plumbing.Hash
+	Refs        map[plumbing.Hash][]string
+	Commits     map[plumbing.Hash]*indexedCommit
+}
+
+// CountCommits returns the number of commits reachable from the given revision.
+func (a *App) CountCommits(repoPath string, rev string) (int, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return 0, err
+	}
+
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return 0, err
+	}
+
+	h, err := r.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := idx.Commits[*h]; !ok {
+		// Only commits reachable from a ref are indexed, so walk the others
+		return countCommitsFrom(r, *h)
+	}
+	return len(idx.reachable(*h)), nil
+}
+
+func countCommitsFrom(r *git.Repository, h plumbing.Hash) (int, error) {
+	iter, err := r.Log(&git.LogOptions{From: h})
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+	count := 0
+	err = iter.ForEach(func(*object.Commit) error {
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant.
+func (a *App) IsAncestor(repoPath string, ancestor string, descendant string) (bool, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false, err
+	}
+
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return false, err
+	}
+
+	ah, err := r.ResolveRevision(plumbing.Revision(ancestor))
+	if err != nil {
+		return false, err
+	}
+	dh, err := r.ResolveRevision(plumbing.Revision(descendant))
+	if err != nil {
+		return false, err
+	}
+
+	return idx.isAncestor(*ah, *dh), nil
+}
+
+// loadHistoryIndex returns an up-to-date history index for the repository,
+// reusing the in-memory or on-disk copy when the refs have not changed.
+// The caller must hold the repository mutex.
+func loadHistoryIndex(repoPath string, r *git.Repository) (*historyIndex, error) {
+	fingerprint, err := refsFingerprint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var idx *historyIndex
+	if cached, ok := historyIndexes.Load(repoPath); ok {
+		idx = cached.(*historyIndex)
+	} else {
+		idx = readHistoryIndex(repoPath)
+	}
+	if idx != nil && idx.Fingerprint == fingerprint {
+		historyIndexes.Store(repoPath, idx)
+		return idx, nil
+	}
+	if idx == nil {
+		idx = &historyIndex{Commits: make(map[plumbing.Hash]*indexedCommit)}
+	}
+
+	idx.Version = historyIndexVersion
+	idx.Fingerprint = fingerprint
+	idx.Refs = getRefMap(r)
+	idx.Tips = idx.Tips[:0]
+	for h := range idx.Refs {
+		idx.Tips = append(idx.Tips, h)
+	}
+	sort.Slice(idx.Tips, func(i, j int) bool { return idx.Tips[i].String() < idx.Tips[j].String() })
+
+	if err := idx.update(r); err != nil {
+		return nil, err
+	}
+
+	historyIndexes.Store(repoPath, idx)
+	writeHistoryIndex(repoPath, idx)
+	return idx, nil
+}
+
+// refsFingerprint hashes the names and targets of all references. It does not
+// read any objects, so it is cheap enough to run on every call.
+func refsFingerprint(r *git.Repository) (string, error) {
+	refs, err := r.References()
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		lines = append(lines, ref.Strings()[0]+" "+ref.Strings()[1])
+		return nil
+	})
+	sort.Strings(lines)
+
+	sum := sha1.Sum([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// update adds every commit reachable from the tips that is not yet indexed,
+// then drops commits that are no longer reachable. Commit data is taken from
+// git's commit-graph file when the repository has one.
+func (idx *historyIndex) update(r *git.Repository) error {
+	var graph commitgraph.Index
+	if fs, ok := r.Storer.(*filesystem.Storage); ok {
+		if g, err := commitgraph.OpenChainOrFileIndex(fs.Filesystem()); err == nil {
+			graph = g
+			defer func() { _ = graph.Close() }()
+		}
+	}
+
+	queue := append([]plumbing.Hash(nil), idx.Tips...)
+	for len(queue) > 0 {
+		h := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if _, ok := idx.Commits[h]; ok {
+			continue
+		}
+
+		entry, ok := lookupCommitGraph(graph, h)
+		if !ok {
+			c, err := r.CommitObject(h)
+			if err != nil {
+				// Not a commit (e.g. a tag on a tree) or missing in a shallow clone.
+				continue
+			}
+			entry = &indexedCommit{
+				Parents: c.ParentHashes,
+				When:    c.Committer.When.Unix(),
+			}
+		}
+
+		idx.Commits[h] = entry
+		queue = append(queue, entry.Parents...)
+	}
+
+	idx.computeGenerations()
+
+	reachable := idx.reachable(idx.Tips...)
+	for h := range idx.Commits {
+		if !reachable[h] {
+			delete(idx.Commits, h)
+		}
+	}
+	return nil
+}
+
+func lookupCommitGraph(graph commitgraph.Index, h plumbing.Hash) (*indexedCommit, bool) {
+	if graph == nil {
+		return nil, false
+	}
+	i, err := graph.GetIndexByHash(h)
+	if err != nil {
+		return nil, false
+	}
+	data, err := graph.GetCommitDataByIndex(i)
+	if err != nil {
+		return nil, false
+	}
+	return &indexedCommit{
+		Parents:    data.ParentHashes,
+		Generation: data.Generation,
+		When:       data.When.Unix(),
+	}, true
+}
+
+// computeGenerations fills in missing generation numbers: one more than the
+// highest generation among the parents, with root commits at generation 1.
+func (idx *historyIndex) computeGenerations() {
+	type frame struct {
+		hash     plumbing.Hash
+		expanded bool
+	}
+
+	for h, c := range idx.Commits {
+		if c.Generation != 0 {
+			continue
+		}
+
+		stack := []frame{{hash: h}}
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			entry := idx.Commits[top.hash]
+			if entry.Generation != 0 {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			if !top.expanded {
+				stack[len(stack)-1].expanded = true
+				for _, p := range entry.Parents {
+					if pc, ok := idx.Commits[p]; ok && pc.Generation == 0 {
+						stack = append(stack, frame{hash: p})
+					}
+				}
+				continue
+			}
+
+			var gen uint64
+			for _, p := range entry.Parents {
+				if pc, ok := idx.Commits[p]; ok && pc.Generation > gen {
+					gen = pc.Generation
+				}
+			}
+			entry.Generation = gen + 1
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// parents returns the indexed parents of a commit, restricted to the first
+// parent when firstParent is set.
+func (idx *historyIndex) parents(h plumbing.Hash, firstParent bool) []plumbing.Hash {
+	c, ok := idx.Commits[h]
+	if !ok {
+		return nil
+	}
+
+	var result []plumbing.Hash
+	for i, p := range c.Parents {
+		if firstParent && i > 0 {
+			break
+		}
+		if _, ok := idx.Commits[p]; ok {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// reachable returns the set of commits reachable from the given commits.
+func (idx *historyIndex) reachable(from ...plumbing.Hash) map[plumbing.Hash]bool {
+	seen := make(map[plumbing.Hash]bool)
+	queue := append([]plumbing.Hash(nil), from...)
+	for len(queue) > 0 {
+		h := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if seen[h] {
+			continue
+		}
+		if _, ok := idx.Commits[h]; !ok {
+			continue
+		}
+		seen[h] = true
+		queue = append(queue, idx.Commits[h].Parents...)
+	}
+	return seen
+}
+
+// isAncestor walks back from descendant, skipping every commit whose
+// generation is already below the one of ancestor.
+func (idx *historyIndex) isAncestor(ancestor, descendant plumbing.Hash) bool {
+	target, ok := idx.Commits[ancestor]
+	if !ok {
+		return false
+	}
+
+	seen := make(map[plumbing.Hash]bool)
+	queue := []plumbing.Hash{descendant}
+	for len(queue) > 0 {
+		h := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if h == ancestor {
+			return true
+		}
+		c, ok := idx.Commits[h]
+		if !ok || seen[h] || c.Generation <= target.Generation {
+			continue
+		}
+		seen[h] = true
+		queue = append(queue, c.Parents...)
+	}
+	return false
+}
+
+// aheadBehind counts the commits reachable from local but not upstream
+// (ahead) and from upstream but not local (behind). Commits are visited in
+// generation order, so every commit has received the marks of all its
+// descendants when it is counted, and the walk stops as soon as only commits
+// reachable from both remain.
+func (idx *historyIndex) aheadBehind(local, upstream plumbing.Hash) (int, int) {
+	const (
+		fromLocal    = 1
+		fromUpstream = 2
+		fromBoth     = fromLocal | fromUpstream
+	)
+
+	marks := make(map[plumbing.Hash]uint8)
+	queue := &generationHeap{idx: idx}
+	pending := 0
+	mark := func(h plumbing.Hash, m uint8) {
+		if _, ok := idx.Commits[h]; !ok {
+			return
+		}
+		old := marks[h]
+		if old|m == old {
+			return
+		}
+		marks[h] = old | m
+		switch {
+		case old == 0:
+			heap.Push(queue, h)
+			if old|m != fromBoth {
+				pending++
+			}
+		case old|m == fromBoth:
+			pending--
+		}
+	}
+	mark(local, fromLocal)
+	mark(upstream, fromUpstream)
+
+	ahead, behind := 0, 0
+	for pending > 0 {
+		h := heap.Pop(queue).(plumbing.Hash)
+		m := marks[h]
+		switch m {
+		case fromLocal:
+			ahead++
+			pending--
+		case fromUpstream:
+			behind++
+			pending--
+		}
+		for _, p := range idx.Commits[h].Parents {
+			mark(p, m)
+		}
+	}
+	return ahead, behind
+}
+
+// generationHeap orders commits by generation number, highest first.
+type generationHeap struct {
+	idx    *historyIndex
+	hashes []plumbing.Hash
+}
+
+func (h *generationHeap) Len() int { return len(h.hashes) }
+
+func (h *generationHeap) Less(i, j int) bool {
+	return h.idx.Commits[h.hashes[i]].Generation > h.idx.Commits[h.hashes[j]].Generation
+}
+
+func (h *generationHeap) Swap(i, j int) { h.hashes[i], h.hashes[j] = h.hashes[j], h.hashes[i] }
+
+func (h *generationHeap) Push(x any) { h.hashes = append(h.hashes, x.(plumbing.Hash)) }
+
+func (h *generationHeap) Pop() any {
+	last := h.hashes[len(h.hashes)-1]
+	h.hashes = h.hashes[:len(h.hashes)-1]
+	return last
+}
+
+// topoOrder returns all indexed commits reachable from the tips in
+// topological order, newest committer date first (like git log --date-order),
+// together with the parents used to order them.
+func (idx *historyIndex) topoOrder(firstParent bool) ([]plumbing.Hash, map[plumbing.Hash][]plumbing.Hash) {
+	included := idx.reachable(idx.Tips...)
+	if firstParent {
+		included = make(map[plumbing.Hash]bool)
+		queue := append([]plumbing.Hash(nil), idx.Tips...)
+		for len(queue) > 0 {
+			h := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			if _, ok := idx.Commits[h]; !ok || included[h] {
+				continue
+			}
+			included[h] = true
+			queue = append(queue, idx.parents(h, true)...)
+		}
+	}
+
+	parents := make(map[plumbing.Hash][]plumbing.Hash, len(included))
+	children := make(map[plumbing.Hash]int, len(included))
+	for h := range included {
+		parents[h] = idx.parents(h, firstParent)
+		for _, p := range parents[h] {
+			children[p]++
+		}
+	}
+
+	ready := &commitHeap{idx: idx}
+	for h := range included {
+		if children[h] == 0 {
+			heap.Push(ready, h)
+		}
+	}
+
+	ordered := make([]plumbing.Hash, 0, len(included))
+	for ready.Len() > 0 {
+		h := heap.Pop(ready).(plumbing.Hash)
+		ordered = append(ordered, h)
+		for _, p := range parents[h] {
+			children[p]--
+			if children[p] == 0 {
+				heap.Push(ready, p)
+			}
+		}
+	}
+
+	return ordered, parents
+}
+
+// commitHeap orders commits by committer date, newest first. Ties are broken
+// by hash so the order is stable between calls.
+type commitHeap struct {
+	idx    *historyIndex
+	hashes []plumbing.Hash
+}
+
+func (h *commitHeap) Len() int { return len(h.hashes) }
+
+func (h *commitHeap) Less(i, j int) bool {
+	ti, tj := h.idx.Commits[h.hashes[i]].When, h.idx.Commits[h.hashes[j]].When
+	if ti != tj {
+		return ti > tj
+	}
+	return h.hashes[i].String() < h.hashes[j].String()
+}
+
+func (h *commitHeap) Swap(i, j int) { h.hashes[i], h.hashes[j] = h.hashes[j], h.hashes[i] }
+
+func (h *commitHeap) Push(x any) { h.hashes = append(h.hashes, x.(plumbing.Hash)) }
+
+func (h *commitHeap) Pop() any {
+	last := h.hashes[len(h.hashes)-1]
+	h.hashes = h.hashes[:len(h.hashes)-1]
+	return last
+}
+
+// historyIndexPath returns the cache file for a repository, named after a hash
+// of its absolute path.
+func historyIndexPath(repoPath string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(absPath))
+	return filepath.Join(cacheDir, "celerix-git", "history", hex.EncodeToString(sum[:])+".gob"), nil
+}
+
+func readHistoryIndex(repoPath string) *historyIndex {
+	p, err := historyIndexPath(repoPath)
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	var idx historyIndex
+	if err := gob.NewDecoder(f).Decode(&idx); err != nil || idx.Version != historyIndexVersion {
+		return nil
+	}
+	return &idx
+}
+
+// writeHistoryIndex persists the index. Failures are ignored: the index is
+// only a cache and will be rebuilt on the next start.
+func writeHistoryIndex(repoPath string, idx *historyIndex) {
+	p, err := historyIndexPath(repoPath)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return
+	}
+
+	tmp := fmt.Sprintf("%s.%d.tmp", p, os.Getpid())
+	f, err := os.Create(tmp)
+	if err != nil {
+		return
+	}
+	err = gob.NewEncoder(f).Encode(idx)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return
+	}
+	_ = os.Rename(tmp, p)
+}
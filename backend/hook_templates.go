@@ -0,0 +1,202 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HookTemplate is a hook script that can be installed into a repository.
+type HookTemplate struct {
+	ID          string `json:"id"`
+	Hook        string `json:"hook"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Content     string `json:"content"`
+}
+
+// builtinHookTemplates are the templates shipped with the app. They are
+// POSIX shell scripts so they also run with the sh of Git for Windows.
+var builtinHookTemplates = []HookTemplate{
+	{
+		ID:          "conventional-commits",
+		Hook:        "commit-msg",
+		Title:       "Conventional Commits",
+		Description: "Rejects commit messages whose subject is not \"type(scope): description\".",
+		Content: `#!/bin/sh
+# Require Conventional Commits subjects, e.g. "feat(ui): add dark mode".
+subject=$(grep -v '^#' "$1" | head -n 1)
+case "$subject" in
+Merge\ *|Revert\ *|fixup!\ *|squash!\ *|amend!\ *) exit 0 ;;
+esac
+if ! printf '%s\n' "$subject" | grep -Eq '^(build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test)(\([^)]+\))?!?: .+'; then
+	echo "The commit subject must follow Conventional Commits: type(scope): description" >&2
+	echo "  got: $subject" >&2
+	exit 1
+fi
+`,
+	},
+	{
+		ID:          "protect-branches",
+		Hook:        "pre-commit",
+		Title:       "Protect main branches",
+		Description: "Prevents committing directly to main or master.",
+		Content: `#!/bin/sh
+# Prevent commits directly on protected branches.
+branch=$(git symbolic-ref --short HEAD 2>/dev/null)
+case "$branch" in
+main|master)
+	echo "Committing directly to $branch is not allowed, create a branch first." >&2
+	exit 1
+	;;
+esac
+`,
+	},
+	{
+		ID:          "no-large-files",
+		Hook:        "pre-commit",
+		Title:       "Block large files",
+		Description: "Rejects staged files larger than 5 MB.",
+		Content: `#!/bin/sh
+# Reject staged files larger than the limit (in bytes).
+limit=5242880
+status=0
+for file in $(git diff --cached --name-only --diff-filter=AM); do
+	size=$(git cat-file -s ":$file" 2>/dev/null || echo 0)
+	if [ "$size" -gt "$limit" ]; then
+		echo "$file is larger than 5 MB ($size bytes)" >&2
+		status=1
+	fi
+done
+exit $status
+`,
+	},
+	{
+		ID:          "no-conflict-markers",
+		Hook:        "pre-commit",
+		Title:       "Block conflict markers",
+		Description: "Rejects staged changes that still contain merge conflict markers.",
+		Content: `#!/bin/sh
+# Reject staged changes containing conflict markers.
+if git diff --cached -U0 | grep -Eq '^\+(<<<<<<<|>>>>>>>)( |$)'; then
+	echo "Staged changes contain merge conflict markers:" >&2
+	git diff --cached --name-only -G'^(<<<<<<<|>>>>>>>)( |$)' >&2
+	exit 1
+fi
+`,
+	},
+	{
+		ID:          "ticket-prefix",
+		Hook:        "prepare-commit-msg",
+		Title:       "Ticket ID from branch",
+		Description: "Prefixes the commit message with the ticket ID in the branch name, e.g. ABC-123.",
+		Content: `#!/bin/sh
+# Prefix the message with the ticket ID from the branch name.
+case "$2" in
+merge|squash|commit) exit 0 ;;
+esac
+branch=$(git symbolic-ref --short HEAD 2>/dev/null)
+ticket=$(printf '%s\n' "$branch" | grep -Eo '[A-Z][A-Z0-9]+-[0-9]+' | head -n 1)
+[ -z "$ticket" ] && exit 0
+if ! head -n 1 "$1" | grep -q "$ticket"; then
+	{ printf '%s: ' "$ticket"; cat "$1"; } > "$1.tmp" && mv "$1.tmp" "$1"
+fi
+`,
+	},
+	{
+		ID:          "run-tests",
+		Hook:        "pre-push",
+		Title:       "Run tests before push",
+		Description: "Runs \"make test\" (or the command in hooks.testCommand) and rejects the push if it fails.",
+		Content: `#!/bin/sh
+# Run the test suite before pushing.
+cmd=$(git config hooks.testCommand)
+[ -z "$cmd" ] && cmd="make test"
+echo "Running $cmd"
+sh -c "$cmd"
+`,
+	},
+}
+
+// GetHookTemplates returns the built-in hook templates and the samples git
+// installed in the repository's hooks directory.
+func (a *App) GetHookTemplates(repoPath string) ([]HookTemplate, error) {
+	templates := append([]HookTemplate{}, builtinHookTemplates...)
+	if repoPath == "" {
+		return templates, nil
+	}
+
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	samples, err := sampleHookTemplates(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return append(templates, samples...), nil
+}
+
+// InstallHookTemplate installs a template as its hook. An installed hook is
+// only replaced if overwrite is set.
+func (a *App) InstallHookTemplate(repoPath string, templateID string, overwrite bool) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	templates, err := sampleHookTemplates(repoPath)
+	if err != nil {
+		return err
+	}
+	templates = append(templates, builtinHookTemplates...)
+
+	var tmpl *HookTemplate
+	for i := range templates {
+		if templates[i].ID == templateID {
+			tmpl = &templates[i]
+			break
+		}
+	}
+	if tmpl == nil {
+		return fmt.Errorf("unknown hook template: %s", templateID)
+	}
+
+	dir, err := hookFileDir(repoPath, tmpl.Hook)
+	if err != nil {
+		return err
+	}
+	if hookStatus(dir, tmpl.Hook).Installed && !overwrite {
+		return fmt.Errorf("the %s hook is already installed", tmpl.Hook)
+	}
+	return writeHook(dir, tmpl.Hook, tmpl.Content)
+}
+
+// sampleHookTemplates reads the <hook>.sample files git init creates. They
+// stay in the git dir even when core.hooksPath points elsewhere.
+func sampleHookTemplates(repoPath string) ([]HookTemplate, error) {
+	gitDir, err := findGitDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	files, _ := filepath.Glob(filepath.Join(commonGitDir(gitDir), "hooks", "*.sample"))
+	sort.Strings(files)
+
+	var templates []HookTemplate
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		hook := strings.TrimSuffix(filepath.Base(f), ".sample")
+		templates = append(templates, HookTemplate{
+			ID:          "sample:" + hook,
+			Hook:        hook,
+			Title:       hook + " sample",
+			Description: "The sample hook installed by git init.",
+			Content:     string(data),
+		})
+	}
+	return templates, nil
+}
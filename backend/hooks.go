@@ -0,0 +1,488 @@
+package backend
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// hookOutputLines is how many lines of hook output are kept for the error
+// message when a hook fails.
+const hookOutputLines = 20
+
+// hookLineLength is the length hook output lines are cut to. The rest of a
+// longer line is read and dropped.
+const hookLineLength = 1024
+
+// HookError is returned when a hook rejects an operation.
+type HookError struct {
+	Hook     string
+	ExitCode int
+	Output   string
+}
+
+func (e *HookError) Error() string {
+	msg := fmt.Sprintf("%s hook failed with exit code %d", e.Hook, e.ExitCode)
+	if e.Output != "" {
+		msg += ":\n" + e.Output
+	}
+	return msg
+}
+
+// hooksDir returns the directory hooks are run from: core.hooksPath, resolved
+// against the worktree like git does, or the hooks directory of the git dir,
+// which linked worktrees share with the main one.
+func hooksDir(repoPath string) (string, error) {
+	cfg, err := loadGitConfig(repoPath)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := cfg.Get("core.hookspath"); ok && p != "" {
+		p = expandHome(p)
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(repoPath, p)
+		}
+		return p, nil
+	}
+	gitDir, err := findGitDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonGitDir(gitDir), "hooks"), nil
+}
+
+// findHook returns the path of an executable hook, or "" if the hook is not
+// installed. Like git, hooks that are not executable are ignored with a hint.
+func (a *App) findHook(repoPath string, name string) (string, error) {
+	dir, err := hooksDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", nil
+	}
+	if goruntime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("The '%s' hook was ignored because it's not set as executable.", name),
+			Percent: -1,
+		})
+		return "", nil
+	}
+	return path, nil
+}
+
+// runHook runs a hook if it is installed, streaming its output through
+// git-progress. A non-zero exit status is returned as a *HookError. The hook
+// is killed when ctx is cancelled.
+func (a *App) runHook(ctx context.Context, repoPath string, name string, args []string, stdin io.Reader, env ...string) error {
+	path, err := a.findHook(repoPath, name)
+	if err != nil || path == "" {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if goruntime.GOOS == "windows" {
+		// Hooks are shell scripts; run them with the sh of Git for Windows.
+		sh, err := gitShell()
+		if err != nil {
+			return fmt.Errorf("cannot run %s hook: %w", name, err)
+		}
+		cmd = exec.CommandContext(ctx, sh, append([]string{filepath.ToSlash(path)}, args...)...)
+	} else {
+		cmd = exec.CommandContext(ctx, path, args...)
+	}
+	cmd.Dir = repoPath
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = stdin
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+		Status:  fmt.Sprintf("Running %s hook...", name),
+		Percent: -1,
+	})
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cannot run %s hook: %w", name, err)
+	}
+	// Children of a killed hook can keep its output open
+	stop := context.AfterFunc(ctx, func() { _ = out.Close() })
+	defer stop()
+
+	var tail []string
+	readErr := readHookOutput(out, func(line string) {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("%s: %s", name, line),
+			Percent: -1,
+		})
+		tail = append(tail, line)
+		if len(tail) > hookOutputLines {
+			tail = tail[1:]
+		}
+	})
+	// The hook would block writing to a pipe nobody reads
+	_, _ = io.Copy(io.Discard, out)
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &HookError{
+			Hook:     name,
+			ExitCode: exitErr.ExitCode(),
+			Output:   strings.TrimSpace(strings.Join(tail, "\n")),
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if readErr != nil {
+		return fmt.Errorf("cannot read the output of the %s hook: %w", name, readErr)
+	}
+	return nil
+}
+
+// readHookOutput calls fn for every line of hook output until EOF. Lines are
+// cut to hookLineLength, so any output can be read.
+func readHookOutput(out io.Reader, fn func(line string)) error {
+	reader := bufio.NewReaderSize(out, hookLineLength)
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line := string(chunk)
+		for isPrefix {
+			// Drop the rest of a long line
+			if _, isPrefix, err = reader.ReadLine(); err != nil {
+				break
+			}
+		}
+		fn(strings.TrimRight(line, "\r"))
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// commitHookEnv is the environment git passes to commit hooks.
+func commitHookEnv(repoPath string) []string {
+	env := []string{"GIT_EDITOR=:"}
+	if gitDir, err := findGitDir(repoPath); err == nil {
+		env = append(env, "GIT_INDEX_FILE="+filepath.Join(gitDir, "index"))
+	}
+	return env
+}
+
+// runCommitMsgHooks writes the message to COMMIT_EDITMSG, runs the
+// prepare-commit-msg and (unless noVerify) commit-msg hooks on it, and returns
+// the message as left by the hooks.
+func (a *App) runCommitMsgHooks(ctx context.Context, repoPath string, msg string, noVerify bool) (string, error) {
+	gitDir, err := findGitDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+	msgFile := filepath.Join(gitDir, "COMMIT_EDITMSG")
+	if err := os.WriteFile(msgFile, []byte(msg+"\n"), 0644); err != nil {
+		return "", err
+	}
+
+	env := commitHookEnv(repoPath)
+	if err := a.runHook(ctx, repoPath, "prepare-commit-msg", []string{msgFile, "message"}, nil, env...); err != nil {
+		return "", err
+	}
+	if !noVerify {
+		if err := a.runHook(ctx, repoPath, "commit-msg", []string{msgFile}, nil, env...); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := os.ReadFile(msgFile)
+	if err != nil {
+		return "", err
+	}
+	msg = cleanupCommitMessage(string(data))
+	if msg == "" {
+		return "", errors.New("aborting commit due to empty commit message")
+	}
+	return msg, nil
+}
+
+// cleanupCommitMessage applies git's "whitespace" cleanup: trailing
+// whitespace is removed, consecutive blank lines are collapsed and leading
+// and trailing blank lines are dropped.
+func cleanupCommitMessage(msg string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prePushInput describes the refs a push updates in the format the pre-push
+// hook reads on stdin: "<local ref> <local sha> <remote ref> <remote sha>".
+// Deletions have "(delete)" and a zero hash as the local side.
+func prePushInput(updates []PushRefUpdate) string {
+	var b strings.Builder
+	for _, u := range updates {
+		local, localHash := u.Local, u.NewHash
+		if u.Status == PushStatusDeleted {
+			local, localHash = "(delete)", plumbing.ZeroHash.String()
+		}
+		remoteHash := u.OldHash
+		if remoteHash == "" {
+			remoteHash = plumbing.ZeroHash.String()
+		}
+		fmt.Fprintf(&b, "%s %s %s %s\n", local, localHash, u.Remote, remoteHash)
+	}
+	return b.String()
+}
+
+// knownHooks are the client-side hooks git runs, listed even when not
+// installed so they can be created from the UI.
+var knownHooks = []string{
+	"applypatch-msg",
+	"pre-applypatch",
+	"post-applypatch",
+	"pre-commit",
+	"pre-merge-commit",
+	"prepare-commit-msg",
+	"commit-msg",
+	"post-commit",
+	"pre-rebase",
+	"post-checkout",
+	"post-merge",
+	"pre-push",
+	"post-rewrite",
+	"pre-auto-gc",
+	"reference-transaction",
+}
+
+// disabledHookSuffix is appended to the file name of a disabled hook, which
+// works on every platform unlike clearing the executable bit.
+const disabledHookSuffix = ".disabled"
+
+// GitHook is a hook in the hooks directory of a repository.
+type GitHook struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Installed  bool   `json:"installed"`
+	Enabled    bool   `json:"enabled"`
+	Executable bool   `json:"executable"`
+}
+
+// HooksInfo lists the hooks of a repository and where they are read from.
+type HooksInfo struct {
+	Dir string `json:"dir"`
+	// HooksPath is core.hooksPath, empty if hooks are read from the git dir.
+	HooksPath string    `json:"hooksPath"`
+	Hooks     []GitHook `json:"hooks"`
+}
+
+// ListHooks returns the known hooks and any other hooks installed in the
+// repository's hooks directory.
+func (a *App) ListHooks(repoPath string) (*HooksInfo, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := hooksDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadGitConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	hooksPath, _ := cfg.Get("core.hookspath")
+
+	names := append([]string{}, knownHooks...)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[name] = true
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), disabledHookSuffix)
+		if e.IsDir() || strings.HasSuffix(name, ".sample") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	info := &HooksInfo{Dir: dir, HooksPath: hooksPath, Hooks: []GitHook{}}
+	for _, name := range names {
+		info.Hooks = append(info.Hooks, hookStatus(dir, name))
+	}
+	return info, nil
+}
+
+// GetHookContent returns the script of an installed hook, enabled or not.
+func (a *App) GetHookContent(repoPath string, name string) (string, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := hookFileDir(repoPath, name)
+	if err != nil {
+		return "", err
+	}
+	hook := hookStatus(dir, name)
+	if !hook.Installed {
+		return "", fmt.Errorf("the %s hook is not installed", name)
+	}
+	data, err := os.ReadFile(hook.Path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SaveHook writes a hook script and makes it executable. A disabled hook
+// stays disabled.
+func (a *App) SaveHook(repoPath string, name string, content string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := hookFileDir(repoPath, name)
+	if err != nil {
+		return err
+	}
+	return writeHook(dir, name, content)
+}
+
+// DeleteHook removes a hook, enabled or not.
+func (a *App) DeleteHook(repoPath string, name string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := hookFileDir(repoPath, name)
+	if err != nil {
+		return err
+	}
+	hook := hookStatus(dir, name)
+	if !hook.Installed {
+		return fmt.Errorf("the %s hook is not installed", name)
+	}
+	return os.Remove(hook.Path)
+}
+
+// SetHookEnabled enables or disables an installed hook. Disabled hooks are
+// renamed so git skips them; enabling also makes the hook executable.
+func (a *App) SetHookEnabled(repoPath string, name string, enabled bool) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := hookFileDir(repoPath, name)
+	if err != nil {
+		return err
+	}
+	hook := hookStatus(dir, name)
+	if !hook.Installed {
+		return fmt.Errorf("the %s hook is not installed", name)
+	}
+
+	active := filepath.Join(dir, name)
+	if enabled {
+		if !hook.Enabled {
+			if err := os.Rename(hook.Path, active); err != nil {
+				return err
+			}
+		}
+		return os.Chmod(active, 0755)
+	}
+	if hook.Enabled {
+		return os.Rename(active, active+disabledHookSuffix)
+	}
+	return nil
+}
+
+// SetHooksPath sets core.hooksPath in the repository config, e.g. to a
+// directory of shared hooks committed to the repository. An empty path
+// restores the default hooks directory.
+func (a *App) SetHooksPath(repoPath string, hooksPath string) error {
+	return a.editConfig(repoPath, ScopeLocal, "core.hooksPath", func([]string) ([]string, error) {
+		if hooksPath == "" {
+			return nil, nil
+		}
+		return []string{hooksPath}, nil
+	})
+}
+
+// hookFileDir validates a hook name and returns the hooks directory.
+func hookFileDir(repoPath string, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid hook name: %s", name)
+	}
+	return hooksDir(repoPath)
+}
+
+func hookStatus(dir string, name string) GitHook {
+	hook := GitHook{Name: name, Path: filepath.Join(dir, name)}
+	if info, err := os.Stat(hook.Path); err == nil && !info.IsDir() {
+		hook.Installed = true
+		hook.Executable = goruntime.GOOS == "windows" || info.Mode()&0111 != 0
+		hook.Enabled = hook.Executable
+		return hook
+	}
+	disabled := hook.Path + disabledHookSuffix
+	if info, err := os.Stat(disabled); err == nil && !info.IsDir() {
+		hook.Path = disabled
+		hook.Installed = true
+		hook.Executable = goruntime.GOOS == "windows" || info.Mode()&0111 != 0
+	}
+	return hook
+}
+
+func writeHook(dir string, name string, content string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	hook := hookStatus(dir, name)
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if err := os.WriteFile(hook.Path, []byte(content), 0755); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(hook.Path, 0755)
+}
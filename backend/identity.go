@@ -0,0 +1,89 @@
+package backend
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrMissingIdentity is returned when neither user.name nor user.email (or
+// their author/committer overrides) are configured.
+var ErrMissingIdentity = errors.New("no git identity configured: set user.name and user.email in the git settings")
+
+// GitIdentity is the user identity git would use in a repository, together
+// with the config file (or identity profile) each value comes from.
+type GitIdentity struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	NameOrigin  string `json:"nameOrigin"`
+	EmailOrigin string `json:"emailOrigin"`
+	SigningKey  string `json:"signingKey"`
+	Profile     string `json:"profile"`
+}
+
+// GetGitIdentity returns the identity used for commits in the repository,
+// including a matching identity profile. An empty repoPath resolves the
+// global identity.
+func (a *App) GetGitIdentity(repoPath string) (*GitIdentity, error) {
+	if repoPath != "" {
+		mu := getRepoMutex(repoPath)
+		mu.Lock()
+		defer mu.Unlock()
+	}
+
+	cfg, err := loadGitConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := matchProfile(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	id := resolveIdentity(cfg, "author")
+	applyProfile(id, profile)
+	return id, nil
+}
+
+// resolveIdentity resolves the identity for a role ("author" or "committer"):
+// <role>.name/<role>.email take precedence over user.name/user.email.
+func resolveIdentity(cfg *gitConfig, role string) *GitIdentity {
+	id := &GitIdentity{}
+	for _, key := range []string{"user.name", role + ".name"} {
+		if e := cfg.entry(key); e != nil {
+			id.Name, id.NameOrigin = e.Value, e.File
+		}
+	}
+	for _, key := range []string{"user.email", role + ".email"} {
+		if e := cfg.entry(key); e != nil {
+			id.Email, id.EmailOrigin = e.Value, e.File
+		}
+	}
+	id.SigningKey, _ = cfg.Get("user.signingkey")
+	return id
+}
+
+// repoSignatures returns the author and committer signatures for new commits
+// and tags in a repository. A matching identity profile overrides git config.
+func repoSignatures(repoPath string) (author *object.Signature, committer *object.Signature, err error) {
+	cfg, err := loadGitConfig(repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	profile, err := matchProfile(repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	sigs := make([]*object.Signature, 2)
+	for i, role := range []string{"author", "committer"} {
+		id := resolveIdentity(cfg, role)
+		applyProfile(id, profile)
+		if id.Name == "" || id.Email == "" {
+			return nil, nil, ErrMissingIdentity
+		}
+		sigs[i] = &object.Signature{Name: id.Name, Email: id.Email, When: now}
+	}
+	return sigs[0], sigs[1], nil
+}
@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// The keyring entry holding the key of the credential store.
+const (
+	keyringService = "celerix-git"
+	keyringAccount = "credential-store"
+)
+
+var (
+	errNoKeyring       = errors.New("no OS keyring is available to protect the saved credentials")
+	errKeyringNotFound = errors.New("the key of the saved credentials is missing")
+)
+
+// The key of the credential store once loaded. Guarded by credentialsMu.
+var credentialKeyCache []byte
+
+// credentialKey returns the key of the credential store. It is kept in the
+// OS keyring: the Windows Credential Manager, the macOS keychain, or the
+// Secret Service (GNOME Keyring, KWallet) elsewhere. Without a keyring no
+// credentials can be saved; a git credential helper can be used instead.
+// With create, a missing key is generated. The caller holds credentialsMu.
+func credentialKey(create bool) ([]byte, error) {
+	if credentialKeyCache != nil {
+		return credentialKeyCache, nil
+	}
+
+	encoded, err := keyringGet()
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != 32 {
+			return nil, errors.New("the key of the saved credentials is invalid")
+		}
+		credentialKeyCache = key
+		return key, nil
+	}
+	if !create || !errors.Is(err, errKeyringNotFound) {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := keyringSet(hex.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	credentialKeyCache = key
+	return key, nil
+}
+
+// deleteCredentialKey removes the key of the credential store from the
+// keyring. The caller holds credentialsMu.
+func deleteCredentialKey() error {
+	credentialKeyCache = nil
+	err := keyringDelete()
+	if errors.Is(err, errKeyringNotFound) || errors.Is(err, errNoKeyring) {
+		return nil
+	}
+	return err
+}
+
+// verifyKeyringSecret makes sure a secret can be read back from the keyring
+// before the credential store relies on it.
+func verifyKeyringSecret(secret string) error {
+	if s, err := keyringGet(); err != nil || s != secret {
+		return errNoKeyring
+	}
+	return nil
+}
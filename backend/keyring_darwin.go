@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The exit status of the security tool for an item that does not exist.
+const securityItemNotFound = 44
+
+// The keychain is used through the security tool. The secret is only ever
+// written to its standard input, never passed as an argument, where other
+// processes could see it.
+
+func keyringGet() (string, error) {
+	cmd := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", securityError(err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func keyringSet(secret string) error {
+	// The secret is hex, which needs no quoting in the interactive mode
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keyringService, keyringAccount, secret))
+	if err := cmd.Run(); err != nil {
+		return securityError(err)
+	}
+	return verifyKeyringSecret(secret)
+}
+
+func keyringDelete() error {
+	cmd := exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", keyringAccount)
+	if err := cmd.Run(); err != nil {
+		return securityError(err)
+	}
+	return nil
+}
+
+func securityError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == securityItemNotFound {
+		return errKeyringNotFound
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return errNoKeyring
+	}
+	return fmt.Errorf("keychain: %w", err)
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !windows && !linux && !freebsd && !openbsd && !netbsd && !dragonfly
+
+package backend
+
+func keyringGet() (string, error) {
+	return "", errNoKeyring
+}
+
+func keyringSet(secret string) error {
+	return errNoKeyring
+}
+
+func keyringDelete() error {
+	return errNoKeyring
+}
@@ -0,0 +1,211 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// The key is kept by the Secret Service (GNOME Keyring, KWallet, KeePassXC),
+// talked to over the session D-Bus.
+
+const (
+	secretsName       = "org.freedesktop.secrets"
+	secretsPath       = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretsService    = "org.freedesktop.Secret.Service"
+	secretsCollection = "org.freedesktop.Secret.Collection"
+	secretsItem       = "org.freedesktop.Secret.Item"
+	secretsPrompt     = "org.freedesktop.Secret.Prompt"
+	// The collection used when the service has no default one
+	secretsLoginCollection = dbus.ObjectPath("/org/freedesktop/secrets/collection/login")
+	// How long to wait for the user to unlock the keyring
+	secretsPromptTimeout = 2 * time.Minute
+)
+
+// secretValue is the Secret structure of the Secret Service API.
+type secretValue struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+type secretSession struct {
+	conn    *dbus.Conn
+	service dbus.BusObject
+	path    dbus.ObjectPath
+}
+
+func keyringAttributes() map[string]string {
+	return map[string]string{"service": keyringService, "account": keyringAccount}
+}
+
+// openSecretSession connects to the Secret Service. The secrets are sent
+// unencrypted over the private connection, as the "plain" algorithm.
+func openSecretSession() (*secretSession, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoKeyring, err)
+	}
+	s := &secretSession{conn: conn, service: conn.Object(secretsName, secretsPath)}
+	var output dbus.Variant
+	err = s.service.Call(secretsService+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &s.path)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %v", errNoKeyring, err)
+	}
+	return s, nil
+}
+
+func (s *secretSession) close() {
+	_ = s.conn.Object(secretsName, s.path).Call("org.freedesktop.Secret.Session.Close", 0).Err
+	s.conn.Close()
+}
+
+// findItems returns the items holding the key, unlocking them if needed.
+func (s *secretSession) findItems() ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.service.Call(secretsService+".SearchItems", 0, keyringAttributes()).Store(&unlocked, &locked)
+	if err != nil {
+		return nil, err
+	}
+	if len(locked) > 0 {
+		if err := s.unlock(locked); err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, locked...)
+	}
+	if len(unlocked) == 0 {
+		return nil, errKeyringNotFound
+	}
+	return unlocked, nil
+}
+
+func (s *secretSession) unlock(paths []dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := s.service.Call(secretsService+".Unlock", 0, paths).Store(&unlocked, &prompt); err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+// prompt shows a prompt of the service, such as the password dialog of a
+// locked keyring, and waits until the user has answered it.
+func (s *secretSession) prompt(path dbus.ObjectPath) error {
+	if path == "/" {
+		return nil
+	}
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(secretsPrompt),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer s.conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 1)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	if err := s.conn.Object(secretsName, path).Call(secretsPrompt+".Prompt", 0, "").Err; err != nil {
+		return err
+	}
+	timeout := time.After(secretsPromptTimeout)
+	for {
+		select {
+		case sig := <-signals:
+			if sig.Path != path || sig.Name != secretsPrompt+".Completed" {
+				continue
+			}
+			if len(sig.Body) > 0 {
+				if dismissed, ok := sig.Body[0].(bool); ok && dismissed {
+					return errors.New("the keyring was not unlocked")
+				}
+			}
+			return nil
+		case <-timeout:
+			return errors.New("the keyring was not unlocked in time")
+		}
+	}
+}
+
+func keyringGet() (string, error) {
+	s, err := openSecretSession()
+	if err != nil {
+		return "", err
+	}
+	defer s.close()
+
+	items, err := s.findItems()
+	if err != nil {
+		return "", err
+	}
+	var secret secretValue
+	if err := s.conn.Object(secretsName, items[0]).Call(secretsItem+".GetSecret", 0, s.path).Store(&secret); err != nil {
+		return "", err
+	}
+	return string(secret.Value), nil
+}
+
+func keyringSet(secret string) error {
+	s, err := openSecretSession()
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	var collection dbus.ObjectPath
+	if err := s.service.Call(secretsService+".ReadAlias", 0, "default").Store(&collection); err != nil {
+		return err
+	}
+	if collection == "/" {
+		collection = secretsLoginCollection
+	}
+	if err := s.unlock([]dbus.ObjectPath{collection}); err != nil {
+		return err
+	}
+
+	properties := map[string]dbus.Variant{
+		secretsItem + ".Label":      dbus.MakeVariant("Celerix Git credentials"),
+		secretsItem + ".Attributes": dbus.MakeVariant(keyringAttributes()),
+	}
+	value := secretValue{Session: s.path, Value: []byte(secret), ContentType: "text/plain"}
+	var item, prompt dbus.ObjectPath
+	err = s.conn.Object(secretsName, collection).Call(secretsCollection+".CreateItem", 0, properties, value, true).Store(&item, &prompt)
+	if err != nil {
+		return err
+	}
+	if err := s.prompt(prompt); err != nil {
+		return err
+	}
+	return verifyKeyringSecret(secret)
+}
+
+func keyringDelete() error {
+	s, err := openSecretSession()
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	items, err := s.findItems()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var prompt dbus.ObjectPath
+		if err := s.conn.Object(secretsName, item).Call(secretsItem+".Delete", 0).Store(&prompt); err != nil {
+			return err
+		}
+		if err := s.prompt(prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// MergeConflictError is returned when changes cannot be combined without
+// manual conflict resolution. Nothing has been changed when it is returned.
+type MergeConflictError struct {
+	Operation string   `json:"operation"`
+	Paths     []string `json:"paths"`
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("%s: conflicts in %s", e.Operation, strings.Join(e.Paths, ", "))
+}
+
+// treeFile is a non-directory entry of a flattened tree.
+type treeFile struct {
+	Hash plumbing.Hash
+	Mode filemode.FileMode
+}
+
+// flattenTree returns all files, symlinks and submodules of a tree by path.
+// A nil tree is empty.
+func flattenTree(tree *object.Tree) (map[string]treeFile, error) {
+	files := make(map[string]treeFile)
+	if tree == nil {
+		return files, nil
+	}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry.Mode == filemode.Dir {
+			continue
+		}
+		files[name] = treeFile{Hash: entry.Hash, Mode: entry.Mode}
+	}
+	return files, nil
+}
+
+// mergeTrees merges the changes from base to ours and from base to theirs,
+// like a three-way merge in git. Files changed on both sides are merged line
+// by line. It returns the merged files and the paths that conflict.
+func mergeTrees(r *git.Repository, base, ours, theirs *object.Tree) (map[string]treeFile, []string, error) {
+	b, err := flattenTree(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	o, err := flattenTree(ours)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := flattenTree(theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paths := make(map[string]bool)
+	for _, files := range []map[string]treeFile{b, o, t} {
+		for p := range files {
+			paths[p] = true
+		}
+	}
+
+	merged := make(map[string]treeFile)
+	var conflicts []string
+	for p := range paths {
+		be, inBase := b[p]
+		oe, inOurs := o[p]
+		te, inTheirs := t[p]
+
+		var result treeFile
+		var keep, ok bool
+		switch {
+		case inOurs == inTheirs && oe == te:
+			result, keep, ok = oe, inOurs, true
+		case inBase == inOurs && be == oe:
+			result, keep, ok = te, inTheirs, true
+		case inBase == inTheirs && be == te:
+			result, keep, ok = oe, inOurs, true
+		case inOurs && inTheirs:
+			result, ok, err = mergeFile(r, be, inBase, oe, te)
+			if err != nil {
+				return nil, nil, err
+			}
+			keep = true
+		}
+		if !ok {
+			conflicts = append(conflicts, p)
+			continue
+		}
+		if keep {
+			merged[p] = result
+		}
+	}
+
+	// A file on one side may be a directory on the other
+	for p := range merged {
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			if _, ok := merged[dir]; ok {
+				conflicts = append(conflicts, dir)
+			}
+		}
+	}
+
+	sort.Strings(conflicts)
+	return merged, dedupe(conflicts), nil
+}
+
+// mergeFile merges a file changed on both sides. Binary files, submodules
+// and symlinks only merge when one side kept the base version, which the
+// caller has already ruled out.
+func mergeFile(r *git.Repository, base treeFile, inBase bool, ours, theirs treeFile) (treeFile, bool, error) {
+	mode, ok := mergeModes(base.Mode, inBase, ours.Mode, theirs.Mode)
+	if !ok || !mergeableMode(mode) || !mergeableMode(ours.Mode) || !mergeableMode(theirs.Mode) {
+		return treeFile{}, false, nil
+	}
+	if ours.Hash == theirs.Hash {
+		return treeFile{Hash: ours.Hash, Mode: mode}, true, nil
+	}
+
+	var baseText string
+	if inBase {
+		if !mergeableMode(base.Mode) {
+			return treeFile{}, false, nil
+		}
+		var err error
+		if baseText, err = readBlob(r, base.Hash); err != nil {
+			return treeFile{}, false, err
+		}
+	}
+	ourText, err := readBlob(r, ours.Hash)
+	if err != nil {
+		return treeFile{}, false, err
+	}
+	theirText, err := readBlob(r, theirs.Hash)
+	if err != nil {
+		return treeFile{}, false, err
+	}
+
+	text, ok := mergeText(baseText, ourText, theirText)
+	if !ok {
+		return treeFile{}, false, nil
+	}
+	h, err := writeBlob(r, []byte(text))
+	if err != nil {
+		return treeFile{}, false, err
+	}
+	return treeFile{Hash: h, Mode: mode}, true, nil
+}
+
+// mergeModes merges the file modes of both sides, e.g. an executable bit
+// added on one side only.
+func mergeModes(base filemode.FileMode, inBase bool, ours, theirs filemode.FileMode) (filemode.FileMode, bool) {
+	switch {
+	case ours == theirs:
+		return ours, true
+	case inBase && base == ours:
+		return theirs, true
+	case inBase && base == theirs:
+		return ours, true
+	}
+	return 0, false
+}
+
+// mergeableMode reports whether files of a mode are merged line by line:
+// regular and executable files, but not symlinks or submodules.
+func mergeableMode(mode filemode.FileMode) bool {
+	return mode.IsRegular() || mode == filemode.Executable
+}
+
+// mergeHunk replaces the base lines [start, end) with lines.
+type mergeHunk struct {
+	start, end int
+	lines      []string
+}
+
+// mergeText merges two versions of a text derived from base line by line,
+// like diff3. Changes that overlap or touch conflict unless they are the
+// same. Binary content never merges.
+func mergeText(base, ours, theirs string) (string, bool) {
+	if strings.IndexByte(base, 0) >= 0 || strings.IndexByte(ours, 0) >= 0 || strings.IndexByte(theirs, 0) >= 0 {
+		return "", false
+	}
+
+	baseLines := splitLines(base)
+	ourHunks := diffHunks(base, ours)
+	theirHunks := diffHunks(base, theirs)
+
+	type sided struct {
+		mergeHunk
+		ours bool
+	}
+	var all []sided
+	for _, h := range ourHunks {
+		all = append(all, sided{h, true})
+	}
+	for _, h := range theirHunks {
+		all = append(all, sided{h, false})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].start < all[j].start })
+
+	var result strings.Builder
+	pos := 0
+	for i := 0; i < len(all); {
+		// Group hunks that overlap or touch
+		start, end := all[i].start, all[i].end
+		j := i + 1
+		for j < len(all) && all[j].start <= end {
+			if all[j].end > end {
+				end = all[j].end
+			}
+			j++
+		}
+		group := all[i:j]
+		i = j
+
+		for _, line := range baseLines[pos:start] {
+			result.WriteString(line)
+		}
+		pos = end
+
+		var ourGroup, theirGroup []mergeHunk
+		for _, h := range group {
+			if h.ours {
+				ourGroup = append(ourGroup, h.mergeHunk)
+			} else {
+				theirGroup = append(theirGroup, h.mergeHunk)
+			}
+		}
+		ourVersion := applyHunks(baseLines, start, end, ourGroup)
+		theirVersion := applyHunks(baseLines, start, end, theirGroup)
+		switch {
+		case len(theirGroup) == 0:
+			result.WriteString(ourVersion)
+		case len(ourGroup) == 0 || ourVersion == theirVersion:
+			result.WriteString(theirVersion)
+		default:
+			return "", false
+		}
+	}
+	for _, line := range baseLines[pos:] {
+		result.WriteString(line)
+	}
+	return result.String(), true
+}
+
+// applyHunks returns the base lines [start, end) with the hunks applied.
+func applyHunks(baseLines []string, start, end int, hunks []mergeHunk) string {
+	var b strings.Builder
+	pos := start
+	for _, h := range hunks {
+		for _, line := range baseLines[pos:h.start] {
+			b.WriteString(line)
+		}
+		for _, line := range h.lines {
+			b.WriteString(line)
+		}
+		pos = h.end
+	}
+	for _, line := range baseLines[pos:end] {
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// diffHunks returns the changes from a to b as hunks over the lines of a.
+func diffHunks(a, b string) []mergeHunk {
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = 0
+	ca, cb, lineArray := dmp.DiffLinesToChars(a, b)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(ca, cb, false), lineArray)
+
+	var hunks []mergeHunk
+	var current *mergeHunk
+	pos := 0
+	for _, d := range diffs {
+		lines := splitLines(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+			pos += len(lines)
+		case diffmatchpatch.DiffDelete:
+			if current == nil {
+				current = &mergeHunk{start: pos, end: pos}
+			}
+			pos += len(lines)
+			current.end = pos
+		case diffmatchpatch.DiffInsert:
+			if current == nil {
+				current = &mergeHunk{start: pos, end: pos}
+			}
+			current.lines = append(current.lines, lines...)
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+	return hunks
+}
+
+// splitLines splits text after each newline; a last line without a newline
+// is kept as is.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeTree stores the files as a tree hierarchy and returns the root hash.
+func writeTree(r *git.Repository, files map[string]treeFile) (plumbing.Hash, error) {
+	type dir struct {
+		files   map[string]treeFile
+		subdirs map[string]*dir
+	}
+	newDir := func() *dir { return &dir{files: map[string]treeFile{}, subdirs: map[string]*dir{}} }
+	root := newDir()
+	for p, f := range files {
+		d := root
+		parts := strings.Split(p, "/")
+		for _, part := range parts[:len(parts)-1] {
+			sub, ok := d.subdirs[part]
+			if !ok {
+				sub = newDir()
+				d.subdirs[part] = sub
+			}
+			d = sub
+		}
+		d.files[parts[len(parts)-1]] = f
+	}
+
+	var store func(d *dir) (plumbing.Hash, error)
+	store = func(d *dir) (plumbing.Hash, error) {
+		tree := &object.Tree{}
+		for name, f := range d.files {
+			tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: f.Mode, Hash: f.Hash})
+		}
+		for name, sub := range d.subdirs {
+			h, err := store(sub)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+			tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: h})
+		}
+		// Git sorts directories as if their name ended with a slash
+		sortKey := func(e object.TreeEntry) string {
+			if e.Mode == filemode.Dir {
+				return e.Name + "/"
+			}
+			return e.Name
+		}
+		sort.Slice(tree.Entries, func(i, j int) bool { return sortKey(tree.Entries[i]) < sortKey(tree.Entries[j]) })
+		return storeObject(r, tree)
+	}
+	return store(root)
+}
+
+func dedupe(values []string) []string {
+	var result []string
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,285 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func TestMergeText(t *testing.T) {
+	base := "one\ntwo\nthree\nfour\nfive\n"
+	tests := []struct {
+		name   string
+		ours   string
+		theirs string
+		want   string
+		ok     bool
+	}{
+		{
+			name:   "unchanged",
+			ours:   base,
+			theirs: base,
+			want:   base,
+			ok:     true,
+		},
+		{
+			name:   "ours only",
+			ours:   "one\nTWO\nthree\nfour\nfive\n",
+			theirs: base,
+			want:   "one\nTWO\nthree\nfour\nfive\n",
+			ok:     true,
+		},
+		{
+			name:   "theirs only",
+			ours:   base,
+			theirs: "one\ntwo\nthree\nfour\nfive\nsix\n",
+			want:   "one\ntwo\nthree\nfour\nfive\nsix\n",
+			ok:     true,
+		},
+		{
+			name:   "separate hunks",
+			ours:   "ONE\ntwo\nthree\nfour\nfive\n",
+			theirs: "one\ntwo\nthree\nfour\nFIVE\n",
+			want:   "ONE\ntwo\nthree\nfour\nFIVE\n",
+			ok:     true,
+		},
+		{
+			name:   "adjacent hunks",
+			ours:   "one\nTWO\nthree\nfour\nfive\n",
+			theirs: "one\ntwo\nTHREE\nfour\nfive\n",
+			ok:     false,
+		},
+		{
+			name:   "overlapping hunks",
+			ours:   "one\nTWO\nTHREE\nfour\nfive\n",
+			theirs: "one\ntwo\n3\nFOUR\nfive\n",
+			ok:     false,
+		},
+		{
+			name:   "identical hunks",
+			ours:   "one\ntwo\n3\nfour\nfive\n",
+			theirs: "one\ntwo\n3\nfour\nfive\n",
+			want:   "one\ntwo\n3\nfour\nfive\n",
+			ok:     true,
+		},
+		{
+			name:   "insertions at the same line",
+			ours:   "one\ntwo\nours\nthree\nfour\nfive\n",
+			theirs: "one\ntwo\ntheirs\nthree\nfour\nfive\n",
+			ok:     false,
+		},
+		{
+			name:   "deletion and separate change",
+			ours:   "one\nthree\nfour\nfive\n",
+			theirs: "one\ntwo\nthree\nfour\nFIVE\n",
+			want:   "one\nthree\nfour\nFIVE\n",
+			ok:     true,
+		},
+		{
+			name:   "missing final newline",
+			ours:   "ONE\ntwo\nthree\nfour\nfive\n",
+			theirs: "one\ntwo\nthree\nfour\nfive",
+			want:   "ONE\ntwo\nthree\nfour\nfive",
+			ok:     true,
+		},
+		{
+			name:   "binary",
+			ours:   "one\x00\ntwo\nthree\nfour\nfive\n",
+			theirs: base,
+			ok:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mergeText(base, tt.ours, tt.theirs)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v (result %q)", ok, tt.ok, got)
+			}
+			if ok && got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeTrees(t *testing.T) {
+	base := map[string]string{
+		"a.txt":     "one\ntwo\nthree\nfour\nfive\n",
+		"b.txt":     "b\n",
+		"dir/c.txt": "c\n",
+	}
+	with := func(changes map[string]string) map[string]string {
+		files := map[string]string{}
+		for p, c := range base {
+			files[p] = c
+		}
+		for p, c := range changes {
+			if c == "" {
+				delete(files, p)
+			} else {
+				files[p] = c
+			}
+		}
+		return files
+	}
+
+	tests := []struct {
+		name      string
+		ours      map[string]string
+		theirs    map[string]string
+		want      map[string]string
+		conflicts []string
+	}{
+		{
+			name:   "changes to different files",
+			ours:   with(map[string]string{"b.txt": "b ours\n"}),
+			theirs: with(map[string]string{"new.txt": "new\n", "dir/c.txt": ""}),
+			want: with(map[string]string{
+				"b.txt":     "b ours\n",
+				"new.txt":   "new\n",
+				"dir/c.txt": "",
+			}),
+		},
+		{
+			name:   "changes to different lines",
+			ours:   with(map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nfive\n"}),
+			theirs: with(map[string]string{"a.txt": "one\ntwo\nthree\nfour\nFIVE\n"}),
+			want:   with(map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nFIVE\n"}),
+		},
+		{
+			name:      "changes to the same line",
+			ours:      with(map[string]string{"a.txt": "one\nours\nthree\nfour\nfive\n"}),
+			theirs:    with(map[string]string{"a.txt": "one\ntheirs\nthree\nfour\nfive\n"}),
+			conflicts: []string{"a.txt"},
+		},
+		{
+			name:   "deleted on both sides",
+			ours:   with(map[string]string{"b.txt": ""}),
+			theirs: with(map[string]string{"b.txt": ""}),
+			want:   with(map[string]string{"b.txt": ""}),
+		},
+		{
+			name:      "deleted and modified",
+			ours:      with(map[string]string{"b.txt": ""}),
+			theirs:    with(map[string]string{"b.txt": "b theirs\n"}),
+			conflicts: []string{"b.txt"},
+		},
+		{
+			name:      "modified and deleted",
+			ours:      with(map[string]string{"b.txt": "b ours\n"}),
+			theirs:    with(map[string]string{"b.txt": ""}),
+			conflicts: []string{"b.txt"},
+		},
+		{
+			name:      "added differently on both sides",
+			ours:      with(map[string]string{"new.txt": "ours\n"}),
+			theirs:    with(map[string]string{"new.txt": "theirs\n"}),
+			conflicts: []string{"new.txt"},
+		},
+		{
+			name:      "file and directory",
+			ours:      with(map[string]string{"x": "file\n"}),
+			theirs:    with(map[string]string{"x/y.txt": "in a directory\n"}),
+			conflicts: []string{"x"},
+		},
+		{
+			name:      "file replacing a directory",
+			ours:      with(map[string]string{"dir/c.txt": "", "dir": "file\n"}),
+			theirs:    with(map[string]string{"dir/d.txt": "d\n"}),
+			conflicts: []string{"dir"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := git.Init(memory.NewStorage(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			merged, conflicts, err := mergeTrees(r, testTree(t, r, base), testTree(t, r, tt.ours), testTree(t, r, tt.theirs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(conflicts, tt.conflicts) {
+				t.Fatalf("conflicts = %v, want %v", conflicts, tt.conflicts)
+			}
+			if len(tt.conflicts) > 0 {
+				return
+			}
+			got := map[string]string{}
+			for p, f := range merged {
+				if got[p], err = readBlob(r, f.Hash); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeTreesModes(t *testing.T) {
+	r, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "one\ntwo\nthree\nfour\nfive\n"
+	base := testTreeFiles(t, r, map[string]testFile{"run.sh": {content, filemode.Regular}})
+	ours := testTreeFiles(t, r, map[string]testFile{"run.sh": {content, filemode.Executable}})
+	theirs := testTreeFiles(t, r, map[string]testFile{"run.sh": {"one\ntwo\nthree\nfour\nFIVE\n", filemode.Regular}})
+
+	merged, conflicts, err := mergeTrees(r, base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) > 0 {
+		t.Fatalf("unexpected conflicts %v", conflicts)
+	}
+	f := merged["run.sh"]
+	if f.Mode != filemode.Executable {
+		t.Errorf("mode = %v, want executable", f.Mode)
+	}
+	if got, _ := readBlob(r, f.Hash); got != "one\ntwo\nthree\nfour\nFIVE\n" {
+		t.Errorf("content = %q", got)
+	}
+}
+
+type testFile struct {
+	content string
+	mode    filemode.FileMode
+}
+
+// testTree stores regular files as a tree.
+func testTree(t *testing.T, r *git.Repository, files map[string]string) *object.Tree {
+	t.Helper()
+	withModes := make(map[string]testFile, len(files))
+	for p, c := range files {
+		withModes[p] = testFile{c, filemode.Regular}
+	}
+	return testTreeFiles(t, r, withModes)
+}
+
+func testTreeFiles(t *testing.T, r *git.Repository, files map[string]testFile) *object.Tree {
+	t.Helper()
+	entries := make(map[string]treeFile, len(files))
+	for p, f := range files {
+		h, err := writeBlob(r, []byte(f.content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[p] = treeFile{Hash: h, Mode: f.mode}
+	}
+	h, err := writeTree(r, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := r.TreeObject(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
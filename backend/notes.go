@@ -0,0 +1,386 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// defaultNotesRef is the notes ref used by git when core.notesRef is not set.
+const defaultNotesRef = "refs/notes/commits"
+
+// notesRefSpec mirrors all notes refs between the local repository and a remote.
+const notesRefSpec = "refs/notes/*:refs/notes/*"
+
+type GitNote struct {
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+}
+
+// GetNotesRefs lists all notes refs in the repository.
+func (a *App) GetNotesRefs(repoPath string) ([]string, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := r.References()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsNote() {
+			result = append(result, ref.Name().String())
+		}
+		return nil
+	})
+	sort.Strings(result)
+	return result, nil
+}
+
+// GetNotes returns the notes attached to a commit across all notes refs.
+func (a *App) GetNotes(repoPath string, commitHash string) ([]GitNote, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := readAllNotes(r)[plumbing.NewHash(commitHash)]
+	if notes == nil {
+		notes = []GitNote{}
+	}
+	return notes, nil
+}
+
+// AddNote attaches a note to a commit. It fails if the commit already has a
+// note in notesRef; use EditNote to replace it. An empty notesRef uses
+// refs/notes/commits.
+func (a *App) AddNote(repoPath string, notesRef string, commitHash string, message string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+
+	return updateNote(r, repoPath, notesRef, commitHash, message, false)
+}
+
+// EditNote sets the note of a commit in notesRef, replacing any existing note.
+func (a *App) EditNote(repoPath string, notesRef string, commitHash string, message string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+
+	return updateNote(r, repoPath, notesRef, commitHash, message, true)
+}
+
+// RemoveNote removes the note of a commit from notesRef.
+func (a *App) RemoveNote(repoPath string, notesRef string, commitHash string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+
+	return updateNote(r, repoPath, notesRef, commitHash, "", true)
+}
+
+// readAllNotes maps commit hashes to their notes from every notes ref.
+// Unreadable notes refs are skipped.
+func readAllNotes(r *git.Repository) map[plumbing.Hash][]GitNote {
+	result := make(map[plumbing.Hash][]GitNote)
+
+	refs, err := r.References()
+	if err != nil {
+		return result
+	}
+
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsNote() {
+			return nil
+		}
+		entries, err := readNotesTree(r, ref.Name())
+		if err != nil {
+			return nil
+		}
+		for target, blobHash := range entries {
+			message, err := readBlob(r, blobHash)
+			if err != nil {
+				continue
+			}
+			result[target] = append(result[target], GitNote{
+				Ref:     ref.Name().String(),
+				Message: strings.TrimRight(message, "\n"),
+			})
+		}
+		return nil
+	})
+
+	return result
+}
+
+// readNotesTree returns the notes of a notes ref as a map from annotated
+// object to note blob. Fanout directories (ab/cdef...) are flattened.
+func readNotesTree(r *git.Repository, notesRef plumbing.ReferenceName) (map[plumbing.Hash]plumbing.Hash, error) {
+	result := make(map[plumbing.Hash]plumbing.Hash)
+
+	ref, err := r.Reference(notesRef, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := r.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !entry.Mode.IsFile() {
+			continue
+		}
+		hex := strings.ReplaceAll(name, "/", "")
+		if len(hex) != 40 {
+			continue
+		}
+		result[plumbing.NewHash(hex)] = entry.Hash
+	}
+
+	return result, nil
+}
+
+// configuredNotesRef returns the notes ref git uses when none is given:
+// $GIT_NOTES_REF, else core.notesRef, else refs/notes/commits.
+func configuredNotesRef(repoPath string) string {
+	if ref := os.Getenv("GIT_NOTES_REF"); ref != "" {
+		return ref
+	}
+	if gc, err := loadGitConfig(repoPath); err == nil {
+		if ref, ok := gc.Get("core.notesRef"); ok && ref != "" {
+			return ref
+		}
+	}
+	return defaultNotesRef
+}
+
+// updateNote writes a new notes commit on notesRef in which the note for
+// commitHash is set to message, or removed when message is empty.
+func updateNote(r *git.Repository, repoPath string, notesRef string, commitHash string, message string, overwrite bool) error {
+	if notesRef == "" {
+		notesRef = configuredNotesRef(repoPath)
+	}
+	if !strings.HasPrefix(notesRef, "refs/notes/") {
+		notesRef = "refs/notes/" + notesRef
+	}
+	refName := plumbing.ReferenceName(notesRef)
+
+	target, err := r.ResolveRevision(plumbing.Revision(commitHash))
+	if err != nil {
+		return err
+	}
+
+	entries, err := readNotesTree(r, refName)
+	if err != nil {
+		return err
+	}
+
+	_, exists := entries[*target]
+	action := "added"
+	switch {
+	case message == "" && !exists:
+		return fmt.Errorf("commit %s has no note in %s", target.String()[:7], notesRef)
+	case message == "":
+		delete(entries, *target)
+		action = "removed"
+	case exists && !overwrite:
+		return fmt.Errorf("commit %s already has a note in %s", target.String()[:7], notesRef)
+	default:
+		if !strings.HasSuffix(message, "\n") {
+			message += "\n"
+		}
+		blobHash, err := writeBlob(r, []byte(message))
+		if err != nil {
+			return err
+		}
+		entries[*target] = blobHash
+	}
+
+	tree := &object.Tree{}
+	for h, blobHash := range entries {
+		tree.Entries = append(tree.Entries, object.TreeEntry{
+			Name: h.String(),
+			Mode: filemode.Regular,
+			Hash: blobHash,
+		})
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool { return tree.Entries[i].Name < tree.Entries[j].Name })
+	treeHash, err := storeObject(r, tree)
+	if err != nil {
+		return err
+	}
+
+	old, err := r.Reference(refName, true)
+	if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+
+	author, committer, err := repoSignatures(repoPath)
+	if err != nil {
+		return err
+	}
+	commit := &object.Commit{
+		Author:    *author,
+		Committer: *committer,
+		Message:   fmt.Sprintf("Notes %s by 'git notes'\n", action),
+		TreeHash:  treeHash,
+	}
+	if old != nil {
+		commit.ParentHashes = []plumbing.Hash{old.Hash()}
+	}
+	notesCommit, err := storeObject(r, commit)
+	if err != nil {
+		return err
+	}
+
+	return r.Storer.CheckAndSetReference(plumbing.NewHashReference(refName, notesCommit), old)
+}
+
+// fetchNotes fetches all notes refs from a remote. Missing notes on the
+// remote are not an error; notes that diverged locally are kept.
+func (a *App) fetchNotes(ctx context.Context, r *git.Repository, repoPath string, remoteName string, url string, auth transport.AuthMethod) error {
+	err := a.withAuth(ctx, repoPath, url, auth, func(auth transport.AuthMethod) error {
+		return r.FetchContext(ctx, &git.FetchOptions{
+			RemoteName: remoteName,
+			RefSpecs:   []config.RefSpec{notesRefSpec},
+			Auth:       auth,
+		})
+	})
+	switch {
+	case err == nil,
+		errors.Is(err, git.NoErrAlreadyUpToDate),
+		errors.Is(err, git.NoMatchingRefSpecError{}):
+		return nil
+	case errors.Is(err, git.ErrForceNeeded):
+		return errors.New("local notes have diverged from the remote and were not updated")
+	}
+	return err
+}
+
+// pushNotes pushes all local notes refs to a URL of a remote.
+func (a *App) pushNotes(ctx context.Context, r *git.Repository, repoPath string, remoteName string, url string, auth transport.AuthMethod) error {
+	hasNotes := false
+	refs, err := r.References()
+	if err != nil {
+		return err
+	}
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsNote() {
+			hasNotes = true
+		}
+		return nil
+	})
+	if !hasNotes {
+		return nil
+	}
+
+	err = a.withAuth(ctx, repoPath, url, auth, func(auth transport.AuthMethod) error {
+		return r.PushContext(ctx, &git.PushOptions{
+			RemoteName: remoteName,
+			RemoteURL:  url,
+			RefSpecs:   []config.RefSpec{notesRefSpec},
+			Auth:       auth,
+		})
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return nil
+}
+
+func readBlob(r *git.Repository, h plumbing.Hash) (string, error) {
+	blob, err := r.BlobObject(h)
+	if err != nil {
+		return "", err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	return string(data), err
+}
+
+func writeBlob(r *git.Repository, data []byte) (plumbing.Hash, error) {
+	obj := r.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.Storer.SetEncodedObject(obj)
+}
+
+// storeObject encodes a tree, commit or tag and writes it to the object store.
+func storeObject(r *git.Repository, o interface {
+	Encode(plumbing.EncodedObject) error
+}) (plumbing.Hash, error) {
+	obj := r.Storer.NewEncodedObject()
+	if err := o.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.Storer.SetEncodedObject(obj)
+}
@@ -0,0 +1,177 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Kinds of long-running operations.
+const (
+	OperationFetch     = "fetch"
+	OperationPull      = "pull"
+	OperationPush      = "push"
+	OperationPreflight = "preflight"
+	OperationClone     = "clone"
+	OperationStats     = "stats"
+	OperationCommit    = "commit"
+	// Deleting a branch on a remote
+	OperationDeleteBranch = "delete-branch"
+	// Listing the branches of a remote, for TestRemote, TestRemoteURL and
+	// UpdateRemoteHead
+	OperationRemoteList = "remote-list"
+)
+
+// Operation is a running long-running operation on a repository, which
+// CancelOperation stops. For a clone, RepoPath is the target directory.
+type Operation struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	RepoPath string `json:"repoPath"`
+}
+
+// ErrOperationCancelled is returned by an operation stopped with
+// CancelOperation.
+var ErrOperationCancelled = errors.New("operation cancelled")
+
+// The names used in the final progress event of a cancelled operation.
+var operationTitles = map[string]string{
+	OperationFetch:        "Fetch",
+	OperationPull:         "Pull",
+	OperationPush:         "Push",
+	OperationPreflight:    "Push preview",
+	OperationClone:        "Clone",
+	OperationStats:        "Loading repository stats",
+	OperationCommit:       "Commit",
+	OperationDeleteBranch: "Branch deletion",
+	OperationRemoteList:   "Remote connection",
+}
+
+type runningOperation struct {
+	Operation
+	seq    uint64
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Problems that did not make the operation fail, reported once it
+	// has finished.
+	warnings []string
+}
+
+// warn records a problem that does not make the operation fail.
+func (op *runningOperation) warn(format string, args ...interface{}) {
+	op.warnings = append(op.warnings, fmt.Sprintf(format, args...))
+}
+
+// The running operations by ID.
+var operations struct {
+	sync.Mutex
+	running map[string]*runningOperation
+	next    uint64
+}
+
+// startOperation registers a cancellable operation. Callers start it before
+// taking the repository lock, so that an operation still waiting for the lock
+// can be cancelled too, and pass its error through finishOperation.
+func (a *App) startOperation(kind string, repoPath string) *runningOperation {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	operations.Lock()
+	if operations.running == nil {
+		operations.running = map[string]*runningOperation{}
+	}
+	operations.next++
+	op := &runningOperation{
+		Operation: Operation{
+			ID:       fmt.Sprintf("%s-%d", kind, operations.next),
+			Kind:     kind,
+			RepoPath: repoPath,
+		},
+		seq:    operations.next,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	operations.running[op.ID] = op
+	list := listOperations()
+	operations.Unlock()
+
+	a.emitOperations(list)
+	return op
+}
+
+// finishOperation unregisters an operation and returns its error. The error
+// of an operation that failed because it was cancelled becomes
+// ErrOperationCancelled, and a final "cancelled" progress event is emitted.
+// The warnings of an operation that succeeded are emitted last, so that they
+// stay visible after its completion status.
+func (a *App) finishOperation(op *runningOperation, err error) error {
+	cancelled := op.ctx.Err() != nil
+	op.cancel()
+
+	operations.Lock()
+	delete(operations.running, op.ID)
+	list := listOperations()
+	operations.Unlock()
+
+	a.emitOperations(list)
+	if err != nil && cancelled {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  operationTitles[op.Kind] + " cancelled",
+			Percent: -1,
+		})
+		return ErrOperationCancelled
+	}
+	if err == nil && len(op.warnings) > 0 {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  strings.Join(op.warnings, "; "),
+			Percent: -1,
+		})
+	}
+	return err
+}
+
+// GetOperations lists the running operations, oldest first.
+func (a *App) GetOperations() []Operation {
+	operations.Lock()
+	defer operations.Unlock()
+	return listOperations()
+}
+
+// CancelOperation stops a running operation. The operation returns
+// ErrOperationCancelled once it has stopped; changes it already made, such
+// as fetched objects, are kept.
+func (a *App) CancelOperation(id string) error {
+	operations.Lock()
+	op, ok := operations.running[id]
+	operations.Unlock()
+	if !ok {
+		return fmt.Errorf("no running operation %s", id)
+	}
+	op.cancel()
+	return nil
+}
+
+// listOperations returns the running operations. The caller holds the lock.
+func listOperations() []Operation {
+	running := make([]*runningOperation, 0, len(operations.running))
+	for _, op := range operations.running {
+		running = append(running, op)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].seq < running[j].seq
+	})
+	list := make([]Operation, len(running))
+	for i, op := range running {
+		list[i] = op.Operation
+	}
+	return list
+}
+
+// emitOperations tells the frontend which operations are running.
+func (a *App) emitOperations(list []Operation) {
+	runtime.EventsEmit(a.ctx, "git-operations", list)
+}
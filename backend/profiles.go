@@ -0,0 +1,236 @@
+package backend
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// IdentityProfile is an identity stored by the app that can be applied to
+// repositories automatically, e.g. a work and an open-source identity.
+type IdentityProfile struct {
+	ID         string `json:"id"`
+	Label      string `json:"label"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	SigningKey string `json:"signingKey"`
+	SSHKeyPath string `json:"sshKeyPath"`
+}
+
+// ProfileRule selects a profile for repositories whose path matches Path
+// (gitdir-style: "~/work/" matches everything below ~/work) or which have a
+// remote URL matching Remote (e.g. "*github.com:acme/*"). Empty patterns are
+// ignored; a rule with both set needs both to match.
+type ProfileRule struct {
+	ProfileID string `json:"profileId"`
+	Path      string `json:"path"`
+	Remote    string `json:"remote"`
+}
+
+// ProfileSettings holds all profiles and the rules mapping repositories to
+// them. Rules are evaluated in order and the first match wins.
+type ProfileSettings struct {
+	Profiles []IdentityProfile `json:"profiles"`
+	Rules    []ProfileRule     `json:"rules"`
+}
+
+var profilesMu sync.Mutex
+
+// GetIdentityProfiles returns the stored profiles and rules.
+func (a *App) GetIdentityProfiles() (*ProfileSettings, error) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	return readProfileSettings()
+}
+
+// SaveIdentityProfiles replaces the stored profiles and rules. Profiles
+// without an ID are assigned one.
+func (a *App) SaveIdentityProfiles(settings ProfileSettings) (*ProfileSettings, error) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	ids := make(map[string]bool)
+	for i := range settings.Profiles {
+		p := &settings.Profiles[i]
+		if p.ID == "" {
+			id, err := newProfileID()
+			if err != nil {
+				return nil, err
+			}
+			p.ID = id
+		}
+		if p.Label == "" {
+			return nil, errors.New("every profile needs a label")
+		}
+		if err := validateEmail(p.Email); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", p.Label, err)
+		}
+		ids[p.ID] = true
+	}
+	for _, rule := range settings.Rules {
+		if !ids[rule.ProfileID] {
+			return nil, fmt.Errorf("rule refers to unknown profile %s", rule.ProfileID)
+		}
+		if rule.Path == "" && rule.Remote == "" {
+			return nil, errors.New("every rule needs a path or remote pattern")
+		}
+	}
+	if settings.Profiles == nil {
+		settings.Profiles = []IdentityProfile{}
+	}
+	if settings.Rules == nil {
+		settings.Rules = []ProfileRule{}
+	}
+
+	p, err := profileSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(p, data, 0600); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// GetRepoProfile returns the profile that applies to a repository, or nil if
+// no rule matches.
+func (a *App) GetRepoProfile(repoPath string) (*IdentityProfile, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+	return matchProfile(repoPath)
+}
+
+// matchProfile returns the profile of the first rule matching the repository.
+func matchProfile(repoPath string) (*IdentityProfile, error) {
+	if repoPath == "" {
+		return nil, nil
+	}
+
+	profilesMu.Lock()
+	settings, err := readProfileSettings()
+	profilesMu.Unlock()
+	if err != nil || len(settings.Rules) == 0 {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var remoteURLs []string
+	if r, err := git.PlainOpen(repoPath); err == nil {
+		if remotes, err := r.Remotes(); err == nil {
+			for _, remote := range remotes {
+				remoteURLs = append(remoteURLs, remote.Config().URLs...)
+			}
+		}
+	}
+
+	foldCase := goruntime.GOOS == "windows" || goruntime.GOOS == "darwin"
+	for _, rule := range settings.Rules {
+		if rule.Path != "" && !matchGitDir(rule.Path, absPath, "", foldCase) {
+			continue
+		}
+		if rule.Remote != "" && !matchAnyRemote(rule.Remote, remoteURLs) {
+			continue
+		}
+		for i := range settings.Profiles {
+			if settings.Profiles[i].ID == rule.ProfileID {
+				return &settings.Profiles[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func matchAnyRemote(pattern string, urls []string) bool {
+	for _, u := range urls {
+		if globMatch(pattern, u, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProfile overrides an identity resolved from git config with the
+// values set in a profile.
+func applyProfile(id *GitIdentity, p *IdentityProfile) {
+	if p == nil {
+		return
+	}
+	origin := "profile: " + p.Label
+	id.Profile = p.Label
+	if p.Name != "" {
+		id.Name, id.NameOrigin = p.Name, origin
+	}
+	if p.Email != "" {
+		id.Email, id.EmailOrigin = p.Email, origin
+	}
+	if p.SigningKey != "" {
+		id.SigningKey = p.SigningKey
+	}
+}
+
+func readProfileSettings() (*ProfileSettings, error) {
+	settings := &ProfileSettings{Profiles: []IdentityProfile{}, Rules: []ProfileRule{}}
+	p, err := profileSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return settings, nil
+}
+
+func profileSettingsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "celerix-git", "profiles.json"), nil
+}
+
+func newProfileID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// expandHome resolves a leading ~/ in a path.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
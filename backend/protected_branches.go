@@ -0,0 +1,170 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+var protectedBranchesMu sync.Mutex
+
+// GetProtectedBranches returns the protected branch patterns of a repository.
+// Commits on these branches are expected to be signed, and the history flags
+// the ones that are not.
+func (a *App) GetProtectedBranches(repoPath string) ([]string, error) {
+	protectedBranchesMu.Lock()
+	defer protectedBranchesMu.Unlock()
+	return repoProtectedBranches(repoPath)
+}
+
+// SetProtectedBranches replaces the protected branch patterns of a
+// repository. A pattern is a branch name that may contain shell wildcards,
+// e.g. "main" or "release/*".
+func (a *App) SetProtectedBranches(repoPath string, patterns []string) ([]string, error) {
+	protectedBranchesMu.Lock()
+	defer protectedBranchesMu.Unlock()
+
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid branch pattern %q: %w", p, err)
+		}
+		cleaned = append(cleaned, p)
+	}
+
+	all, err := readProtectedBranches()
+	if err != nil {
+		return nil, err
+	}
+	if len(cleaned) == 0 {
+		delete(all, repoSettingsKey(repoPath))
+	} else {
+		all[repoSettingsKey(repoPath)] = cleaned
+	}
+
+	p, err := protectedBranchesPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(p, data, 0600); err != nil {
+		return nil, err
+	}
+	return cleaned, nil
+}
+
+// protectedCommits returns the commits reachable from a protected branch,
+// local or remote-tracking, or nil if the repository protects no branch.
+func protectedCommits(repoPath string, r *git.Repository, idx *historyIndex) map[plumbing.Hash]bool {
+	protectedBranchesMu.Lock()
+	patterns, err := repoProtectedBranches(repoPath)
+	protectedBranchesMu.Unlock()
+	if err != nil || len(patterns) == 0 {
+		return nil
+	}
+
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return nil
+	}
+	refs, err := r.References()
+	if err != nil {
+		return nil
+	}
+	var tips []plumbing.Hash
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		var branch string
+		switch {
+		case ref.Name().IsBranch():
+			branch = ref.Name().Short()
+		case ref.Name().IsRemote():
+			// The remote branch it is fetched from, e.g. main of origin/main
+			_, merge := remoteTrackingSource(cfg, ref.Name())
+			if merge == "" {
+				return nil
+			}
+			branch = merge.Short()
+		default:
+			return nil
+		}
+		if matchBranchPattern(patterns, branch) {
+			tips = append(tips, ref.Hash())
+		}
+		return nil
+	})
+	if len(tips) == 0 {
+		return nil
+	}
+	return idx.reachable(tips...)
+}
+
+func matchBranchPattern(patterns []string, branch string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func repoProtectedBranches(repoPath string) ([]string, error) {
+	all, err := readProtectedBranches()
+	if err != nil {
+		return nil, err
+	}
+	patterns := all[repoSettingsKey(repoPath)]
+	if patterns == nil {
+		patterns = []string{}
+	}
+	return patterns, nil
+}
+
+func readProtectedBranches() (map[string][]string, error) {
+	all := make(map[string][]string)
+	p, err := protectedBranchesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return all, nil
+}
+
+func protectedBranchesPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "celerix-git", "protected-branches.json"), nil
+}
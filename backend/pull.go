@@ -0,0 +1,867 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Pull integration modes for PullOptions.Mode.
+const (
+	// PullModeDefault follows branch.<name>.rebase, pull.rebase and pull.ff
+	// like git pull.
+	PullModeDefault = ""
+	// PullModeFastForward only updates the branch when it has no commits of
+	// its own.
+	PullModeFastForward = "ff-only"
+	// PullModeMerge creates a merge commit when the branches have diverged.
+	PullModeMerge = "merge"
+	// PullModeRebase replays the local commits on top of the upstream.
+	PullModeRebase = "rebase"
+)
+
+// PullOptions controls a pull. Remote defaults to the upstream remote of the
+// current branch. Autostash keeps local changes that touch files changed by
+// the pull by merging them back afterwards; rebase.autoStash and
+// merge.autoStash enable it as well.
+type PullOptions struct {
+	Remote    string `json:"remote"`
+	Mode      string `json:"mode"`
+	Autostash bool   `json:"autostash"`
+}
+
+// pullResult is the outcome of the integration step, computed before the
+// branch or the worktree is touched.
+type pullResult struct {
+	target  plumbing.Hash
+	summary string
+	// rewritten maps replayed commits to their new hashes, for post-rewrite.
+	rewritten [][2]plumbing.Hash
+	merged    bool
+}
+
+// PullWithOptions fetches the remote and integrates the upstream of the
+// current branch by fast-forward, merge or rebase. All commits are created
+// before anything is changed, so a conflict aborts the pull with the branch,
+// index and worktree untouched. Local changes are carried over; changes to
+// files the pull also changes need autostash. A cancelled pull stops before
+// the branch is updated.
+func (a *App) PullWithOptions(repoPath string, opts PullOptions) (err error) {
+	op := a.startOperation(OperationPull, repoPath)
+	defer func() { err = a.finishOperation(op, err) }()
+
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return err
+	}
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+
+	fail := func(err error) error {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Pull failed: %v", err),
+			Percent: -1,
+		})
+		return err
+	}
+
+	w, err := r.Worktree()
+	if err != nil {
+		return fail(err)
+	}
+	headRef, err := r.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return fail(err)
+	}
+	if headRef.Type() != plumbing.SymbolicReference {
+		return fail(errors.New("cannot pull: HEAD is not on a branch"))
+	}
+	branch := headRef.Target().Short()
+
+	remote, err := resolveRemote(repoPath, r, opts.Remote, false)
+	if err != nil {
+		return fail(err)
+	}
+	if err := a.fetchRemote(op, r, repoPath, remote, false); err != nil {
+		return err
+	}
+
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return fail(err)
+	}
+	name := remote.Config().Name
+	upstream, merge, err := pullUpstream(r, cfg, name, branch)
+	if err != nil {
+		return fail(err)
+	}
+
+	gc, err := loadGitConfig(repoPath)
+	if err != nil {
+		return fail(err)
+	}
+	mode, noFF, keepMerges := pullMode(gc, branch, opts.Mode)
+	autostash := opts.Autostash
+	if mode == PullModeRebase {
+		autostash = autostash || gc.GetBool("rebase.autostash", false)
+	} else {
+		autostash = autostash || gc.GetBool("merge.autostash", false)
+	}
+
+	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+		Status:  fmt.Sprintf("Integrating %s/%s...", name, merge.Short()),
+		Percent: -1,
+	})
+
+	var result *pullResult
+	old := plumbing.ZeroHash
+	head, err := r.Reference(headRef.Target(), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// An unborn branch simply starts at the upstream
+		result = &pullResult{target: upstream, summary: "checked out"}
+	} else if err != nil {
+		return fail(err)
+	} else {
+		old = head.Hash()
+		message := fmt.Sprintf("Merge branch '%s' of %s", merge.Short(), remote.Config().URLs[0])
+		if branch != "main" && branch != "master" {
+			message += " into " + branch
+		}
+		result, err = integratePull(op.ctx, repoPath, r, old, upstream, mode, noFF, keepMerges, message+"\n")
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	// Nothing has changed yet, so this is the last point to stop
+	if err := op.ctx.Err(); err != nil {
+		return err
+	}
+	if result.target != old {
+		if err := updateWorktree(r, w, repoPath, headRef.Target(), result.target, autostash, mode == PullModeRebase); err != nil {
+			return fail(err)
+		}
+	}
+
+	// Like git, failing post-merge and post-rewrite hooks do not undo the pull
+	if result.merged {
+		_ = a.runHook(op.ctx, repoPath, "post-merge", []string{"0"}, nil)
+	}
+	if len(result.rewritten) > 0 {
+		var stdin strings.Builder
+		for _, pair := range result.rewritten {
+			fmt.Fprintf(&stdin, "%s %s\n", pair[0], pair[1])
+		}
+		_ = a.runHook(op.ctx, repoPath, "post-rewrite", []string{"rebase"}, strings.NewReader(stdin.String()))
+	}
+
+	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+		Status:  fmt.Sprintf("Pull completed (%s)", result.summary),
+		Percent: 100,
+	})
+
+	return nil
+}
+
+// pullUpstream returns the commit to integrate: the remote-tracking ref of
+// the branch's upstream when pulling from the upstream remote, else the
+// remote branch of the same name.
+func pullUpstream(r *git.Repository, cfg *config.Config, remoteName string, branch string) (plumbing.Hash, plumbing.ReferenceName, error) {
+	merge := plumbing.NewBranchReferenceName(branch)
+	if upstream, m := upstreamOf(cfg, branch); upstream == remoteName && m != "" {
+		merge = m
+	}
+	tracking := trackingRef(cfg, remoteName, merge)
+	if tracking == "" {
+		return plumbing.ZeroHash, "", fmt.Errorf("%s is not fetched by remote %s", merge.Short(), remoteName)
+	}
+	ref, err := r.Reference(tracking, true)
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("no branch %s on remote %s to pull", merge.Short(), remoteName)
+	}
+	return ref.Hash(), merge, nil
+}
+
+// pullMode resolves the integration mode like git pull. noFF is set when
+// pull.ff=false asks for a merge commit even when a fast-forward is possible;
+// keepMerges when pull.rebase=merges asks to keep local merge commits.
+func pullMode(gc *gitConfig, branch string, mode string) (string, bool, bool) {
+	rebase, ok := gc.Get("branch." + branch + ".rebase")
+	if !ok {
+		rebase, ok = gc.Get("pull.rebase")
+	}
+	rebase = strings.ToLower(rebase)
+	keepMerges := rebase == "merges" || rebase == "m"
+
+	if mode == PullModeDefault {
+		switch {
+		case ok && (keepMerges || rebase == "interactive" || rebase == "i" || parseConfigBool(rebase)):
+			mode = PullModeRebase
+		default:
+			mode = PullModeMerge
+			if ff, _ := gc.Get("pull.ff"); strings.ToLower(ff) == "only" {
+				mode = PullModeFastForward
+			}
+		}
+	}
+
+	noFF := false
+	if mode == PullModeMerge {
+		if ff, ok := gc.Get("pull.ff"); ok && ff != "only" && !parseConfigBool(ff) {
+			noFF = true
+		}
+	}
+	return mode, noFF, keepMerges
+}
+
+// integratePull computes the new tip of the branch. Merge and rebase commits
+// are written to the object store but no ref is moved.
+func integratePull(ctx context.Context, repoPath string, r *git.Repository, head, upstream plumbing.Hash, mode string, noFF, keepMerges bool, message string) (*pullResult, error) {
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if head == upstream || isFastForward(r, idx, upstream, head) {
+		return &pullResult{target: head, summary: "already up to date"}, nil
+	}
+	if isFastForward(r, idx, head, upstream) && (!noFF || mode != PullModeMerge) {
+		return &pullResult{target: upstream, summary: "fast-forward", merged: mode != PullModeRebase}, nil
+	}
+
+	switch mode {
+	case PullModeFastForward:
+		return nil, errors.New("not possible to fast-forward: the branch and its upstream have diverged")
+	case PullModeMerge:
+		target, err := mergeCommits(ctx, repoPath, r, head, upstream, message)
+		if err != nil {
+			return nil, err
+		}
+		return &pullResult{target: target, summary: "merged", merged: true}, nil
+	case PullModeRebase:
+		return rebaseCommits(ctx, repoPath, r, idx, head, upstream, keepMerges)
+	}
+	return nil, fmt.Errorf("unknown pull mode %q", mode)
+}
+
+// mergeCommits creates a merge commit of head and upstream.
+func mergeCommits(ctx context.Context, repoPath string, r *git.Repository, head, upstream plumbing.Hash, message string) (plumbing.Hash, error) {
+	headCommit, err := r.CommitObject(head)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	upstreamCommit, err := r.CommitObject(upstream)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	bases, err := headCommit.MergeBase(upstreamCommit)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(bases) > 1 {
+		return plumbing.ZeroHash, errors.New("the branches have more than one merge base; merge them on the command line")
+	}
+	var baseTree *object.Tree
+	if len(bases) == 1 {
+		if baseTree, err = bases[0].Tree(); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	upstreamTree, err := upstreamCommit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	files, conflicts, err := mergeTrees(r, baseTree, headTree, upstreamTree)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(conflicts) > 0 {
+		return plumbing.ZeroHash, &MergeConflictError{Operation: "cannot merge", Paths: conflicts}
+	}
+	treeHash, err := writeTree(r, files)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	author, committer, err := repoSignatures(repoPath)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return writeCommit(ctx, repoPath, r, &object.Commit{
+		Author:       *author,
+		Committer:    *committer,
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{head, upstream},
+	})
+}
+
+// rebaseCommits replays the commits of head that upstream does not have on
+// top of upstream, oldest first, keeping their authors and messages. Commits
+// whose changes are already upstream are dropped; commits that were empty to
+// begin with are kept. Local merge commits are dropped like git rebase does,
+// unless keepMerges asks to keep them, which is not supported.
+func rebaseCommits(ctx context.Context, repoPath string, r *git.Repository, idx *historyIndex, head, upstream plumbing.Hash, keepMerges bool) (*pullResult, error) {
+	inUpstream := idx.reachable(upstream)
+	var todo []plumbing.Hash
+	for h := range idx.reachable(head) {
+		if !inUpstream[h] {
+			todo = append(todo, h)
+		}
+	}
+	sort.Slice(todo, func(i, j int) bool {
+		ci, cj := idx.Commits[todo[i]], idx.Commits[todo[j]]
+		if ci.Generation != cj.Generation {
+			return ci.Generation < cj.Generation
+		}
+		return ci.When < cj.When
+	})
+
+	_, committer, err := repoSignatures(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	onto, err := r.CommitObject(upstream)
+	if err != nil {
+		return nil, err
+	}
+	ontoTree, err := onto.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pullResult{}
+	for _, h := range todo {
+		c, err := r.CommitObject(h)
+		if err != nil {
+			return nil, err
+		}
+		if c.NumParents() > 1 {
+			if keepMerges {
+				return nil, errors.New("rebasing merge commits is not supported; set pull.rebase to true or merge instead")
+			}
+			continue
+		}
+
+		tree, err := c.Tree()
+		if err != nil {
+			return nil, err
+		}
+		var parentTree *object.Tree
+		if c.NumParents() == 1 {
+			parent, err := c.Parent(0)
+			if err != nil {
+				return nil, err
+			}
+			if parentTree, err = parent.Tree(); err != nil {
+				return nil, err
+			}
+		}
+		wasEmpty := parentTree != nil && parentTree.Hash == tree.Hash
+
+		files, conflicts, err := mergeTrees(r, parentTree, ontoTree, tree)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			subject, _, _ := strings.Cut(c.Message, "\n")
+			return nil, &MergeConflictError{
+				Operation: fmt.Sprintf("cannot rebase %s (%s)", h.String()[:7], subject),
+				Paths:     conflicts,
+			}
+		}
+		treeHash, err := writeTree(r, files)
+		if err != nil {
+			return nil, err
+		}
+		if treeHash == ontoTree.Hash && !wasEmpty {
+			continue
+		}
+
+		newHash, err := writeCommit(ctx, repoPath, r, &object.Commit{
+			Author:       c.Author,
+			Committer:    *committer,
+			Message:      c.Message,
+			TreeHash:     treeHash,
+			ParentHashes: []plumbing.Hash{onto.Hash},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if onto, err = r.CommitObject(newHash); err != nil {
+			return nil, err
+		}
+		if ontoTree, err = onto.Tree(); err != nil {
+			return nil, err
+		}
+		result.rewritten = append(result.rewritten, [2]plumbing.Hash{h, newHash})
+	}
+
+	result.target = onto.Hash
+	result.summary = fmt.Sprintf("rebased %d commit(s)", len(result.rewritten))
+	return result, nil
+}
+
+// writeCommit stores a commit, signing it when commit.gpgSign is set.
+func writeCommit(ctx context.Context, repoPath string, r *git.Repository, commit *object.Commit) (plumbing.Hash, error) {
+	signing, err := repoSigningConfig(repoPath)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if signing.SignCommit {
+		signer, err := signing.signer(ctx)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		encoded := &plumbing.MemoryObject{}
+		if err := commit.EncodeWithoutSignature(encoded); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		reader, err := encoded.Reader()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		sig, err := signer.Sign(reader)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		commit.PGPSignature = string(sig)
+	}
+	return storeObject(r, commit)
+}
+
+// localFile is the worktree state of a path with local changes.
+type localFile struct {
+	exists  bool
+	content []byte
+	mode    filemode.FileMode
+}
+
+// updateWorktree moves the branch to target and checks it out, carrying over
+// local changes like git. Paths the pull leaves alone keep their index entry
+// and worktree file as they are. Paths it changes are updated if they have no
+// local changes; staged and unstaged changes to them are merged into the new
+// version when autostash is set and refused otherwise. A rebase refuses any
+// local change without autostash, like git. Nothing is changed when an error
+// is returned: everything is computed first, and files already written are
+// restored if a later step fails.
+func updateWorktree(r *git.Repository, w *git.Worktree, repoPath string, branch plumbing.ReferenceName, target plumbing.Hash, autostash bool, refuseDirty bool) error {
+	status, err := w.Status()
+	if err != nil {
+		return err
+	}
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return err
+	}
+
+	var oldTree *object.Tree
+	old := plumbing.ZeroHash
+	if ref, err := r.Reference(branch, true); err == nil {
+		old = ref.Hash()
+		c, err := r.CommitObject(old)
+		if err != nil {
+			return err
+		}
+		if oldTree, err = c.Tree(); err != nil {
+			return err
+		}
+	}
+	targetCommit, err := r.CommitObject(target)
+	if err != nil {
+		return err
+	}
+	newTree, err := targetCommit.Tree()
+	if err != nil {
+		return err
+	}
+	oldFiles, err := flattenTree(oldTree)
+	if err != nil {
+		return err
+	}
+	newFiles, err := flattenTree(newTree)
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]*index.Entry, len(idx.Entries))
+	for _, e := range idx.Entries {
+		// index.Merged is 1 in go-git, but merged entries are stored
+		// with stage 0.
+		if e.Stage != 0 {
+			return errors.New("cannot pull: you have unmerged files; resolve them first")
+		}
+		entries[e.Name] = e
+	}
+	if refuseDirty && !autostash {
+		for _, s := range status {
+			if s.Staging != git.Untracked && (s.Staging != git.Unmodified || s.Worktree != git.Unmodified) {
+				return errors.New("cannot pull with rebase: you have local changes; commit them or enable autostash")
+			}
+		}
+	}
+
+	// Only the paths the pull changes are touched
+	var touched []string
+	for p, o := range oldFiles {
+		if n, ok := newFiles[p]; !ok || n != o {
+			touched = append(touched, p)
+		}
+	}
+	for p := range newFiles {
+		if _, ok := oldFiles[p]; !ok {
+			touched = append(touched, p)
+		}
+	}
+	sort.Strings(touched)
+
+	var updates []pathUpdate
+	var conflicts []string
+	for _, p := range touched {
+		u, ok, err := planPathUpdate(r, repoPath, p, oldFiles, newFiles, entries[p], status, autostash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			conflicts = append(conflicts, p)
+			continue
+		}
+		updates = append(updates, u)
+	}
+	if len(conflicts) > 0 {
+		operation := "local changes would be overwritten by the pull"
+		if autostash {
+			operation = "cannot apply local changes after the pull"
+		}
+		return &MergeConflictError{Operation: operation, Paths: conflicts}
+	}
+
+	// Merged index versions are new objects; storing them changes nothing
+	// that is visible
+	for i := range updates {
+		u := &updates[i]
+		if u.inIndex && u.entry.Hash.IsZero() {
+			h, err := writeBlob(r, u.staged.content)
+			if err != nil {
+				return err
+			}
+			u.entry = treeFile{Hash: h, Mode: u.staged.mode}
+		}
+	}
+
+	written, err := writeWorktreeUpdates(repoPath, updates)
+	if err != nil {
+		restoreWorktree(repoPath, written)
+		return err
+	}
+
+	updated := make(map[string]bool, len(updates))
+	for _, u := range updates {
+		updated[u.path] = true
+	}
+	newIdx := &index.Index{Version: idx.Version}
+	for _, e := range idx.Entries {
+		if !updated[e.Name] {
+			newIdx.Entries = append(newIdx.Entries, e)
+		}
+	}
+	for _, u := range updates {
+		if u.inIndex {
+			newIdx.Entries = append(newIdx.Entries, newIndexEntry(repoPath, u))
+		}
+	}
+	sort.Slice(newIdx.Entries, func(i, j int) bool { return newIdx.Entries[i].Name < newIdx.Entries[j].Name })
+	if err := r.Storer.SetIndex(newIdx); err != nil {
+		restoreWorktree(repoPath, written)
+		return err
+	}
+
+	if !old.IsZero() {
+		err = r.Storer.SetReference(plumbing.NewHashReference("ORIG_HEAD", old))
+	}
+	if err == nil {
+		err = r.Storer.SetReference(plumbing.NewHashReference(branch, target))
+	}
+	if err != nil {
+		_ = r.Storer.SetIndex(idx)
+		restoreWorktree(repoPath, written)
+		return err
+	}
+	return nil
+}
+
+// pathUpdate is the new state of a path the pull changes.
+type pathUpdate struct {
+	path string
+	// The new index entry, if inIndex. A zero hash stands for the merged
+	// version in staged, which is still to be stored.
+	inIndex bool
+	entry   treeFile
+	staged  localFile
+	// The new worktree file, and the one it replaces. Submodules are only
+	// updated in the index.
+	file      localFile
+	previous  localFile
+	writeFile bool
+}
+
+// planPathUpdate computes the new index entry and worktree file of a path
+// the pull changes. ok is false when its local changes are in the way.
+func planPathUpdate(r *git.Repository, repoPath string, p string, oldFiles, newFiles map[string]treeFile, entry *index.Entry, status git.Status, autostash bool) (pathUpdate, bool, error) {
+	o, inOld := oldFiles[p]
+	n, inNew := newFiles[p]
+	inIndex := entry != nil
+	var staged treeFile
+	if inIndex {
+		staged = treeFile{Hash: entry.Hash, Mode: entry.Mode}
+	}
+	indexClean := inIndex == inOld && staged == o
+	u := pathUpdate{path: p, inIndex: inNew, entry: n}
+
+	if o.Mode == filemode.Submodule || n.Mode == filemode.Submodule || staged.Mode == filemode.Submodule {
+		return u, indexClean, nil
+	}
+
+	newVersion := localFile{}
+	if inNew {
+		content, err := readBlob(r, n.Hash)
+		if err != nil {
+			return u, false, err
+		}
+		newVersion = localFile{exists: true, content: []byte(content), mode: n.Mode}
+	}
+
+	local, err := readLocalFile(filepath.Join(repoPath, filepath.FromSlash(p)))
+	if err != nil {
+		return u, false, err
+	}
+	if goruntime.GOOS == "windows" && inIndex && local.exists && local.mode != filemode.Symlink {
+		// The worktree has no executable bit to compare
+		local.mode = staged.Mode
+	}
+	u.previous = local
+	u.file = newVersion
+	u.writeFile = true
+
+	worktreeClean := local.exists == inIndex &&
+		(!inIndex || local.mode == staged.Mode && blobHash(local.content) == staged.Hash)
+	if !inIndex && local.exists {
+		if s, ok := status[p]; ok && s.Worktree == git.Untracked && !sameLocalFile(local, newVersion) {
+			return u, false, fmt.Errorf("the untracked file %s would be overwritten by the pull", p)
+		}
+		// Ignored files are overwritten, like git does
+		worktreeClean = indexClean
+	}
+	if indexClean && worktreeClean {
+		return u, true, nil
+	}
+	if !autostash {
+		return u, false, nil
+	}
+
+	// Like a stash that keeps the index, carry over the staged version and
+	// the worktree file separately
+	stagedVersion := localFile{}
+	if !indexClean {
+		if inIndex {
+			content, err := readBlob(r, staged.Hash)
+			if err != nil {
+				return u, false, err
+			}
+			stagedVersion = localFile{exists: true, content: []byte(content), mode: staged.Mode}
+		}
+		merged, ok, err := mergeLocalFile(r, o, inOld, n, inNew, stagedVersion)
+		if err != nil || !ok {
+			return u, false, err
+		}
+		u.inIndex = merged.exists
+		if merged.exists && !sameLocalFile(merged, newVersion) {
+			u.entry = treeFile{}
+			u.staged = merged
+		}
+		stagedVersion = merged
+	} else {
+		stagedVersion = newVersion
+	}
+
+	if worktreeClean {
+		u.file = stagedVersion
+		return u, true, nil
+	}
+	merged, ok, err := mergeLocalFile(r, o, inOld, n, inNew, local)
+	if err != nil || !ok {
+		return u, false, err
+	}
+	u.file = merged
+	return u, true, nil
+}
+
+// writeWorktreeUpdates writes the new worktree files, removals first so that
+// a directory can become a file. It returns the updates it applied.
+func writeWorktreeUpdates(repoPath string, updates []pathUpdate) ([]pathUpdate, error) {
+	var written []pathUpdate
+	for _, removals := range []bool{true, false} {
+		for _, u := range updates {
+			if !u.writeFile || u.file.exists == removals || sameLocalFile(u.file, u.previous) {
+				continue
+			}
+			fsPath := filepath.Join(repoPath, filepath.FromSlash(u.path))
+			if err := writeLocalFile(fsPath, u.file); err != nil {
+				return written, err
+			}
+			written = append(written, u)
+			if removals {
+				removeEmptyDirs(repoPath, filepath.Dir(fsPath))
+			}
+		}
+	}
+	return written, nil
+}
+
+// restoreWorktree puts back the files replaced by writeWorktreeUpdates.
+func restoreWorktree(repoPath string, written []pathUpdate) {
+	for i := len(written) - 1; i >= 0; i-- {
+		u := written[i]
+		_ = writeLocalFile(filepath.Join(repoPath, filepath.FromSlash(u.path)), u.previous)
+	}
+}
+
+// newIndexEntry returns the index entry of an updated path. Entries of files
+// that match the worktree get its stat data, so git does not rehash them.
+func newIndexEntry(repoPath string, u pathUpdate) *index.Entry {
+	e := &index.Entry{Name: u.path, Hash: u.entry.Hash, Mode: u.entry.Mode}
+	if u.writeFile && u.file.exists && u.file.mode == u.entry.Mode && blobHash(u.file.content) == u.entry.Hash {
+		if info, err := os.Lstat(filepath.Join(repoPath, filepath.FromSlash(u.path))); err == nil {
+			e.ModifiedAt = info.ModTime()
+			e.Size = uint32(info.Size())
+		}
+	}
+	return e
+}
+
+// removeEmptyDirs removes dir and its parents up to the worktree root while
+// they are empty, like git does when the last file of a directory goes.
+func removeEmptyDirs(repoPath string, dir string) {
+	root := filepath.Clean(repoPath)
+	for dir != root && strings.HasPrefix(dir, root) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func sameLocalFile(a, b localFile) bool {
+	return a.exists == b.exists && a.mode == b.mode && string(a.content) == string(b.content)
+}
+
+func blobHash(content []byte) plumbing.Hash {
+	return plumbing.ComputeHash(plumbing.BlobObject, content)
+}
+
+// mergeLocalFile merges the local version of a file into the version the
+// pull checks out, with the old HEAD version as base.
+func mergeLocalFile(r *git.Repository, old treeFile, inOld bool, updated treeFile, inNew bool, local localFile) (localFile, bool, error) {
+	if !local.exists || !inNew {
+		// Deleted on one side and changed on the other, unless both deleted
+		return localFile{}, !local.exists && !inNew, nil
+	}
+
+	updatedContent, err := readBlob(r, updated.Hash)
+	if err != nil {
+		return localFile{}, false, err
+	}
+	if local.mode == updated.Mode && string(local.content) == updatedContent {
+		return local, true, nil
+	}
+	mode, ok := mergeModes(old.Mode, inOld, updated.Mode, local.mode)
+	if !ok || !mergeableMode(mode) || !mergeableMode(local.mode) || !mergeableMode(updated.Mode) {
+		return localFile{}, false, nil
+	}
+
+	var oldContent string
+	if inOld {
+		if !mergeableMode(old.Mode) {
+			return localFile{}, false, nil
+		}
+		if oldContent, err = readBlob(r, old.Hash); err != nil {
+			return localFile{}, false, err
+		}
+	}
+	content, ok := mergeText(oldContent, updatedContent, string(local.content))
+	if !ok {
+		return localFile{}, false, nil
+	}
+	return localFile{exists: true, content: []byte(content), mode: mode}, true, nil
+}
+
+func readLocalFile(path string) (localFile, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return localFile{}, nil
+	}
+	if err != nil {
+		return localFile{}, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return localFile{}, err
+		}
+		return localFile{exists: true, content: []byte(target), mode: filemode.Symlink}, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return localFile{}, err
+	}
+	mode := filemode.Regular
+	if info.Mode()&0o111 != 0 {
+		mode = filemode.Executable
+	}
+	return localFile{exists: true, content: content, mode: mode}, nil
+}
+
+func writeLocalFile(path string, f localFile) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !f.exists {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if f.mode == filemode.Symlink {
+		return os.Symlink(string(f.content), path)
+	}
+	perm := os.FileMode(0o644)
+	if f.mode == filemode.Executable {
+		perm = 0o755
+	}
+	return os.WriteFile(path, f.content, perm)
+}
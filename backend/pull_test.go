@@ -0,0 +1,344 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// pullTestRepo is a repository checked out at base, with target a child of
+// base that changes a.txt, deletes c.txt and adds d.txt. b.txt and e.txt are
+// left alone by the pull.
+type pullTestRepo struct {
+	dir    string
+	r      *git.Repository
+	w      *git.Worktree
+	base   plumbing.Hash
+	target plumbing.Hash
+}
+
+const pullTestA = "one\ntwo\nthree\nfour\nfive\n"
+
+func newPullTestRepo(t *testing.T) *pullTestRepo {
+	t.Helper()
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseFiles := map[string]string{
+		"a.txt":     pullTestA,
+		"b.txt":     "b\n",
+		"c.txt":     "c\n",
+		"dir/e.txt": "e\n",
+	}
+	base := testCommit(t, r, baseFiles, plumbing.ZeroHash)
+	targetFiles := map[string]string{
+		"a.txt":     "one\ntwo\nthree\nfour\nFIVE\n",
+		"b.txt":     "b\n",
+		"d.txt":     "d\n",
+		"dir/e.txt": "e\n",
+	}
+	target := testCommit(t, r, targetFiles, base)
+
+	if err := r.Storer.SetReference(plumbing.NewHashReference(plumbing.Master, base)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reset(&git.ResetOptions{Commit: base, Mode: git.HardReset}); err != nil {
+		t.Fatal(err)
+	}
+	return &pullTestRepo{dir: dir, r: r, w: w, base: base, target: target}
+}
+
+func testCommit(t *testing.T, r *git.Repository, files map[string]string, parent plumbing.Hash) plumbing.Hash {
+	t.Helper()
+	sig := object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+	commit := &object.Commit{
+		Author:    sig,
+		Committer: sig,
+		Message:   "commit\n",
+		TreeHash:  testTree(t, r, files).Hash,
+	}
+	if !parent.IsZero() {
+		commit.ParentHashes = []plumbing.Hash{parent}
+	}
+	h, err := storeObject(r, commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func (p *pullTestRepo) write(t *testing.T, path string, content string) {
+	t.Helper()
+	full := filepath.Join(p.dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (p *pullTestRepo) stage(t *testing.T, path string, content string) {
+	t.Helper()
+	p.write(t, path, content)
+	if _, err := p.w.Add(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// file returns the content of a worktree file, or "<missing>".
+func (p *pullTestRepo) file(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(p.dir, filepath.FromSlash(path)))
+	if os.IsNotExist(err) {
+		return "<missing>"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// staged returns the content of the index entry of a path, or "<missing>".
+func (p *pullTestRepo) staged(t *testing.T, path string) string {
+	t.Helper()
+	idx, err := p.r.Storer.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := idx.Entry(path)
+	if err != nil {
+		return "<missing>"
+	}
+	content, err := readBlob(p.r, e.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func (p *pullTestRepo) head(t *testing.T) plumbing.Hash {
+	t.Helper()
+	ref, err := p.r.Reference(plumbing.Master, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref.Hash()
+}
+
+func TestUpdateWorktree(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, p *pullTestRepo)
+		autostash  bool
+		rebase     bool
+		wantErr    bool
+		wantFiles  map[string]string
+		wantStaged map[string]string
+	}{
+		{
+			name:  "clean",
+			setup: func(t *testing.T, p *pullTestRepo) {},
+			wantFiles: map[string]string{
+				"a.txt": "one\ntwo\nthree\nfour\nFIVE\n",
+				"c.txt": "<missing>",
+				"d.txt": "d\n",
+			},
+			wantStaged: map[string]string{
+				"a.txt": "one\ntwo\nthree\nfour\nFIVE\n",
+				"c.txt": "<missing>",
+				"d.txt": "d\n",
+			},
+		},
+		{
+			name: "staged then edited file the pull leaves alone",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.stage(t, "b.txt", "b staged\n")
+				p.write(t, "b.txt", "b edited\n")
+			},
+			wantFiles:  map[string]string{"b.txt": "b edited\n", "a.txt": "one\ntwo\nthree\nfour\nFIVE\n"},
+			wantStaged: map[string]string{"b.txt": "b staged\n", "a.txt": "one\ntwo\nthree\nfour\nFIVE\n"},
+		},
+		{
+			name: "staged deletion and staged new file",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				if _, err := p.w.Remove("dir/e.txt"); err != nil {
+					t.Fatal(err)
+				}
+				p.stage(t, "new.txt", "new\n")
+			},
+			wantFiles:  map[string]string{"dir/e.txt": "<missing>", "new.txt": "new\n"},
+			wantStaged: map[string]string{"dir/e.txt": "<missing>", "new.txt": "new\n"},
+		},
+		{
+			name: "unstaged change to a file the pull leaves alone",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.write(t, "b.txt", "b edited\n")
+			},
+			wantFiles:  map[string]string{"b.txt": "b edited\n"},
+			wantStaged: map[string]string{"b.txt": "b\n"},
+		},
+		{
+			name: "untracked file",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.write(t, "untracked.txt", "mine\n")
+			},
+			wantFiles:  map[string]string{"untracked.txt": "mine\n", "d.txt": "d\n"},
+			wantStaged: map[string]string{"untracked.txt": "<missing>"},
+		},
+		{
+			name: "untracked file in the way",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.write(t, "d.txt", "mine\n")
+			},
+			wantErr:   true,
+			wantFiles: map[string]string{"d.txt": "mine\n", "a.txt": pullTestA},
+		},
+		{
+			name: "unstaged change to a pulled file",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.write(t, "a.txt", "ONE\ntwo\nthree\nfour\nfive\n")
+			},
+			wantErr:    true,
+			wantFiles:  map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nfive\n"},
+			wantStaged: map[string]string{"a.txt": pullTestA},
+		},
+		{
+			name: "staged change to a pulled file",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.stage(t, "a.txt", "ONE\ntwo\nthree\nfour\nfive\n")
+			},
+			wantErr:    true,
+			wantFiles:  map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nfive\n"},
+			wantStaged: map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nfive\n"},
+		},
+		{
+			name: "autostash merges staged and unstaged changes",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.stage(t, "a.txt", "ONE\ntwo\nthree\nfour\nfive\n")
+				p.write(t, "a.txt", "ONE\nTWO\nthree\nfour\nfive\n")
+			},
+			autostash:  true,
+			wantFiles:  map[string]string{"a.txt": "ONE\nTWO\nthree\nfour\nFIVE\n"},
+			wantStaged: map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nFIVE\n"},
+		},
+		{
+			name: "autostash with a conflicting change",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.write(t, "a.txt", "one\ntwo\nthree\nfour\nfive!\n")
+			},
+			autostash:  true,
+			wantErr:    true,
+			wantFiles:  map[string]string{"a.txt": "one\ntwo\nthree\nfour\nfive!\n"},
+			wantStaged: map[string]string{"a.txt": pullTestA},
+		},
+		{
+			name: "autostash with a deleted pulled file",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				if _, err := p.w.Remove("a.txt"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			autostash:  true,
+			wantErr:    true,
+			wantFiles:  map[string]string{"a.txt": "<missing>"},
+			wantStaged: map[string]string{"a.txt": "<missing>"},
+		},
+		{
+			name: "rebase with local changes",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.write(t, "b.txt", "b edited\n")
+			},
+			rebase:    true,
+			wantErr:   true,
+			wantFiles: map[string]string{"b.txt": "b edited\n", "a.txt": pullTestA},
+		},
+		{
+			name: "rebase with local changes and autostash",
+			setup: func(t *testing.T, p *pullTestRepo) {
+				p.write(t, "b.txt", "b edited\n")
+			},
+			rebase:     true,
+			autostash:  true,
+			wantFiles:  map[string]string{"b.txt": "b edited\n", "a.txt": "one\ntwo\nthree\nfour\nFIVE\n"},
+			wantStaged: map[string]string{"b.txt": "b\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPullTestRepo(t)
+			tt.setup(t, p)
+
+			err := updateWorktree(p.r, p.w, p.dir, plumbing.Master, p.target, tt.autostash, tt.rebase)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			wantHead := p.target
+			if tt.wantErr {
+				wantHead = p.base
+			}
+			if got := p.head(t); got != wantHead {
+				t.Errorf("branch at %s, want %s", got, wantHead)
+			}
+			for path, want := range tt.wantFiles {
+				if got := p.file(t, path); got != want {
+					t.Errorf("worktree %s = %q, want %q", path, got, want)
+				}
+			}
+			for path, want := range tt.wantStaged {
+				if got := p.staged(t, path); got != want {
+					t.Errorf("index %s = %q, want %q", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateWorktreeConflictError(t *testing.T) {
+	p := newPullTestRepo(t)
+	p.write(t, "a.txt", "changed\n")
+
+	err := updateWorktree(p.r, p.w, p.dir, plumbing.Master, p.target, false, false)
+	var conflict *MergeConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("err = %v, want a MergeConflictError", err)
+	}
+	if len(conflict.Paths) != 1 || conflict.Paths[0] != "a.txt" {
+		t.Errorf("paths = %v, want [a.txt]", conflict.Paths)
+	}
+}
+
+func TestUpdateWorktreeStatusAfterPull(t *testing.T) {
+	p := newPullTestRepo(t)
+	p.stage(t, "b.txt", "b staged\n")
+
+	if err := updateWorktree(p.r, p.w, p.dir, plumbing.Master, p.target, false, false); err != nil {
+		t.Fatal(err)
+	}
+	status, err := p.w.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, s := range status {
+		if path == "b.txt" {
+			if s.Staging != git.Modified || s.Worktree != git.Unmodified {
+				t.Errorf("b.txt status %c%c, want staged modification", s.Staging, s.Worktree)
+			}
+			continue
+		}
+		t.Errorf("unexpected change %c%c %s", s.Staging, s.Worktree, path)
+	}
+}
@@ -0,0 +1,375 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// Statuses of a ref in a push plan.
+const (
+	PushStatusNew         = "new"
+	PushStatusFastForward = "fast-forward"
+	PushStatusForced      = "forced"
+	PushStatusDeleted     = "deleted"
+	PushStatusRejected    = "rejected"
+)
+
+// PushRefUpdate is one remote ref a push would change. Local is empty for
+// deletions; OldHash is empty when the remote ref does not exist yet.
+type PushRefUpdate struct {
+	Local   string `json:"local"`
+	Remote  string `json:"remote"`
+	OldHash string `json:"oldHash"`
+	NewHash string `json:"newHash"`
+	Status  string `json:"status"`
+	// Reason explains a rejection: non-fast-forward, fetch first (the
+	// remote has commits that are not available locally), stale info (the
+	// force-with-lease expectation does not hold) or already exists (tags
+	// are not moved without force).
+	Reason string `json:"reason"`
+}
+
+// PushPreview is the outcome of a dry run against a push URL. Refs that are
+// already up to date are left out.
+type PushPreview struct {
+	Remote  string          `json:"remote"`
+	URL     string          `json:"url"`
+	Updates []PushRefUpdate `json:"updates"`
+}
+
+// PreflightPush connects to every push URL of the remote and reports what a
+// push with the same options would update, without changing anything.
+func (a *App) PreflightPush(repoPath string, opts PushOptions) (previews []PushPreview, err error) {
+	op := a.startOperation(OperationPreflight, repoPath)
+	defer func() { err = a.finishOperation(op, err) }()
+
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := resolveRemote(repoPath, r, opts.Remote, true)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	specs, branch, err := pushSpecs(r, cfg, remote, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, url := range pushURLs(cfg, remote) {
+		remoteRefs, err := a.lsRemote(op.ctx, repoPath, url)
+		if err != nil {
+			return nil, err
+		}
+		updates, err := planPush(repoPath, r, cfg, remote.Config().Name, specs, branch, remoteRefs, opts)
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, PushPreview{
+			Remote:  remote.Config().Name,
+			URL:     url,
+			Updates: updates,
+		})
+	}
+	return previews, nil
+}
+
+// pushSpecs returns the refspecs a push sends, like git push: the given
+// branch and tags, or the configured push refspecs if neither is given.
+// SetUpstream without a branch pushes the current branch. The returned
+// branch ref is the one pushed by name, if any. opts.Branch is filled in.
+func pushSpecs(r *git.Repository, cfg *config.Config, remote *git.Remote, opts *PushOptions) ([]config.RefSpec, plumbing.ReferenceName, error) {
+	if opts.Branch == "" && opts.SetUpstream {
+		if opts.Branch = currentBranch(r); opts.Branch == "" {
+			return nil, "", errors.New("cannot set the upstream: HEAD is not on a branch")
+		}
+	}
+	if opts.ExpectedHash != "" {
+		if opts.Branch == "" {
+			return nil, "", errors.New("an expected remote hash needs a branch to push")
+		}
+		if !plumbing.IsHash(opts.ExpectedHash) {
+			return nil, "", fmt.Errorf("invalid expected hash %q", opts.ExpectedHash)
+		}
+	}
+
+	force := ""
+	if opts.Force {
+		force = "+"
+	}
+
+	var specs []config.RefSpec
+	var dst plumbing.ReferenceName
+	if opts.Branch != "" {
+		src := plumbing.NewBranchReferenceName(opts.Branch)
+		if _, err := r.Reference(src, false); err != nil {
+			return nil, "", fmt.Errorf("branch %s: %w", opts.Branch, err)
+		}
+		dst = src
+		if opts.RemoteBranch != "" {
+			dst = plumbing.NewBranchReferenceName(opts.RemoteBranch)
+		} else if upstream, merge := upstreamOf(cfg, opts.Branch); upstream == remote.Config().Name && merge != "" {
+			dst = merge
+		}
+		specs = append(specs, config.RefSpec(force+src.String()+":"+dst.String()))
+	}
+	if opts.AllTags {
+		specs = append(specs, config.RefSpec(force+"refs/tags/*:refs/tags/*"))
+	}
+	for _, tag := range opts.Tags {
+		ref := plumbing.NewTagReferenceName(tag)
+		if _, err := r.Reference(ref, false); err != nil {
+			return nil, "", fmt.Errorf("tag %s: %w", tag, err)
+		}
+		specs = append(specs, config.RefSpec(force+ref.String()+":"+ref.String()))
+	}
+
+	if len(specs) == 0 {
+		for _, spec := range pushRefSpecs(cfg, remote) {
+			if opts.Force && !spec.IsForceUpdate() && !spec.IsDelete() {
+				spec = config.RefSpec("+" + spec.String())
+			}
+			specs = append(specs, spec)
+		}
+	}
+	for _, spec := range specs {
+		if err := spec.Validate(); err != nil {
+			return nil, "", fmt.Errorf("invalid refspec %s: %w", spec, err)
+		}
+	}
+	return specs, dst, nil
+}
+
+// planPush expands the refspecs against the local refs and compares the
+// result with the refs advertised by the remote, deciding for each ref
+// whether git would accept the update. branchDst is the destination of
+// opts.Branch, the only ref opts.ExpectedHash applies to.
+func planPush(repoPath string, r *git.Repository, cfg *config.Config, remoteName string, specs []config.RefSpec, branchDst plumbing.ReferenceName, remoteRefs map[plumbing.ReferenceName]plumbing.Hash, opts PushOptions) ([]PushRefUpdate, error) {
+	refs, err := r.References()
+	if err != nil {
+		return nil, err
+	}
+	var local []*plumbing.Reference
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && (ref.Name().IsBranch() || ref.Name().IsTag() || ref.Name().IsNote()) {
+			local = append(local, ref)
+		}
+		return nil
+	})
+
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[plumbing.ReferenceName]bool)
+	var updates []PushRefUpdate
+	add := func(src *plumbing.Reference, dst plumbing.ReferenceName, force bool) {
+		if seen[dst] {
+			return
+		}
+		seen[dst] = true
+
+		old := remoteRefs[dst]
+		u := PushRefUpdate{Remote: dst.String()}
+		if !old.IsZero() {
+			u.OldHash = old.String()
+		}
+		if src == nil {
+			if old.IsZero() {
+				return
+			}
+			u.Status = PushStatusDeleted
+			updates = append(updates, u)
+			return
+		}
+		u.Local = src.Name().String()
+		u.NewHash = src.Hash().String()
+		if old == src.Hash() {
+			return
+		}
+
+		if opts.ForceWithLease {
+			expected := plumbing.ZeroHash
+			if dst == branchDst && opts.ExpectedHash != "" {
+				expected = plumbing.NewHash(opts.ExpectedHash)
+			} else if tracking := trackingRef(cfg, remoteName, dst); tracking != "" {
+				if ref, err := r.Reference(tracking, true); err == nil {
+					expected = ref.Hash()
+				}
+			}
+			if old != expected {
+				u.Status, u.Reason = PushStatusRejected, "stale info"
+				updates = append(updates, u)
+				return
+			}
+			force = true
+		}
+
+		switch {
+		case old.IsZero():
+			u.Status = PushStatusNew
+		case dst.IsTag() && !force:
+			u.Status, u.Reason = PushStatusRejected, "already exists"
+		case isFastForward(r, idx, old, src.Hash()):
+			u.Status = PushStatusFastForward
+		case force:
+			u.Status = PushStatusForced
+		case !hasObject(r, old):
+			u.Status, u.Reason = PushStatusRejected, "fetch first"
+		default:
+			u.Status, u.Reason = PushStatusRejected, "non-fast-forward"
+		}
+		updates = append(updates, u)
+	}
+
+	for _, spec := range specs {
+		if spec.IsDelete() {
+			add(nil, spec.Dst(""), true)
+			continue
+		}
+		for _, ref := range local {
+			if spec.Match(ref.Name()) {
+				add(ref, spec.Dst(ref.Name()), spec.IsForceUpdate())
+			}
+		}
+	}
+
+	sort.SliceStable(updates, func(i, j int) bool { return updates[i].Remote < updates[j].Remote })
+	return updates, nil
+}
+
+// isFastForward reports whether new descends from old, using the history
+// index when both commits are indexed.
+func isFastForward(r *git.Repository, idx *historyIndex, old, new plumbing.Hash) bool {
+	if _, ok := idx.Commits[old]; ok {
+		if _, ok := idx.Commits[new]; ok {
+			return idx.isAncestor(old, new)
+		}
+	}
+	oldCommit, err := r.CommitObject(old)
+	if err != nil {
+		return false
+	}
+	newCommit, err := r.CommitObject(new)
+	if err != nil {
+		return false
+	}
+	ok, err := oldCommit.IsAncestor(newCommit)
+	return err == nil && ok
+}
+
+func hasObject(r *git.Repository, h plumbing.Hash) bool {
+	_, err := r.Storer.EncodedObject(plumbing.AnyObject, h)
+	return err == nil
+}
+
+// pushRejection describes the rejected refs of a plan as an error, or
+// returns nil if none are rejected.
+func pushRejection(updates []PushRefUpdate) error {
+	var lines []string
+	for _, u := range updates {
+		if u.Status == PushStatusRejected {
+			lines = append(lines, fmt.Sprintf("! [rejected] %s -> %s (%s)",
+				plumbing.ReferenceName(u.Local).Short(), plumbing.ReferenceName(u.Remote).Short(), u.Reason))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("push rejected:\n%s", strings.Join(lines, "\n"))
+}
+
+// exactPushSpecs turns a plan back into one refspec per ref, so that the push
+// sends exactly what was planned. Forced updates get a "+".
+func exactPushSpecs(updates []PushRefUpdate) []config.RefSpec {
+	var specs []config.RefSpec
+	for _, u := range updates {
+		switch u.Status {
+		case PushStatusDeleted:
+			specs = append(specs, config.RefSpec(":"+u.Remote))
+		case PushStatusForced:
+			specs = append(specs, config.RefSpec("+"+u.Local+":"+u.Remote))
+		case PushStatusNew, PushStatusFastForward:
+			specs = append(specs, config.RefSpec(u.Local+":"+u.Remote))
+		}
+	}
+	return specs
+}
+
+// pushLeases returns, for a force-with-lease push, the remote values every
+// updated ref must still have when the push happens.
+func pushLeases(updates []PushRefUpdate) []config.RefSpec {
+	var specs []config.RefSpec
+	for _, u := range updates {
+		if u.OldHash != "" && u.Status != PushStatusRejected {
+			specs = append(specs, config.RefSpec(u.OldHash+":"+u.Remote))
+		}
+	}
+	return specs
+}
+
+// pushOptionsMap converts "key=value" push options to the form go-git sends.
+// An option without a value is sent as "key=".
+func pushOptionsMap(options []string) map[string]string {
+	if len(options) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(options))
+	for _, o := range trimNonEmpty(options) {
+		key, value, _ := strings.Cut(o, "=")
+		result[key] = value
+	}
+	return result
+}
+
+// lsRemote returns the refs advertised by a remote URL. An empty repository
+// has none.
+func (a *App) lsRemote(ctx context.Context, repoPath string, url string) (map[plumbing.ReferenceName]plumbing.Hash, error) {
+	auth, err := a.getAuth(ctx, repoPath, url)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "anonymous", URLs: []string{url}})
+	var refs []*plumbing.Reference
+	err = a.withAuth(ctx, repoPath, url, auth, func(auth transport.AuthMethod) error {
+		refs, err = remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+		return err
+	})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return map[plumbing.ReferenceName]plumbing.Hash{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[plumbing.ReferenceName]plumbing.Hash, len(refs))
+	for _, ref := range refs {
+		if ref.Type() == plumbing.HashReference {
+			result[ref.Name()] = ref.Hash()
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,590 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// repoConfig reads the repository config. go-git adds remote.<name>.pushurl
+// values to the remote's URLs, which would be written back as url values;
+// they are removed so the config can be saved safely.
+func repoConfig(r *git.Repository) (*config.Config, error) {
+	cfg, err := r.Config()
+	if err != nil {
+		return nil, err
+	}
+	for name, rc := range cfg.Remotes {
+		rc.URLs = cfg.Raw.Section("remote").Subsection(name).Options.GetAll("url")
+	}
+	return cfg, nil
+}
+
+// currentBranch returns the name of the checked out branch, or "" if HEAD is
+// detached or unborn.
+func currentBranch(r *git.Repository) string {
+	head, err := r.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return ""
+	}
+	return head.Name().Short()
+}
+
+// upstreamOf returns the configured upstream remote and merge ref of a
+// branch, or empty values if the branch has none.
+func upstreamOf(cfg *config.Config, branch string) (string, plumbing.ReferenceName) {
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Remote == "." {
+		return "", ""
+	}
+	return b.Remote, b.Merge
+}
+
+// defaultRemote resolves the remote used when none is given, like git: the
+// upstream remote of the current branch (for pushes branch.<name>.pushRemote
+// and remote.pushDefault take precedence), then origin. A repository with a
+// single remote uses it whatever its name.
+func defaultRemote(repoPath string, r *git.Repository, push bool) (string, error) {
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return "", err
+	}
+
+	branch := currentBranch(r)
+	if push {
+		// Like git, these may also be set in the global or system config
+		gc, err := loadGitConfig(repoPath)
+		if err != nil {
+			return "", err
+		}
+		if branch != "" {
+			if name, _ := gc.Get("branch." + branch + ".pushremote"); name != "" {
+				return name, nil
+			}
+		}
+		if name, _ := gc.Get("remote.pushdefault"); name != "" {
+			return name, nil
+		}
+	}
+	if branch != "" {
+		if name, _ := upstreamOf(cfg, branch); name != "" {
+			return name, nil
+		}
+	}
+
+	if _, ok := cfg.Remotes["origin"]; ok {
+		return "origin", nil
+	}
+	if len(cfg.Remotes) == 1 {
+		for name := range cfg.Remotes {
+			return name, nil
+		}
+	}
+	if len(cfg.Remotes) == 0 {
+		return "", fmt.Errorf("no remote repository is configured")
+	}
+	return "", fmt.Errorf("no default remote: the current branch has no upstream and there is no origin remote")
+}
+
+// resolveRemote returns the named remote, or the default remote if name is
+// empty.
+func resolveRemote(repoPath string, r *git.Repository, name string, push bool) (*git.Remote, error) {
+	if name == "" {
+		var err error
+		if name, err = defaultRemote(repoPath, r, push); err != nil {
+			return nil, err
+		}
+	}
+	remote, err := r.Remote(name)
+	if err != nil {
+		return nil, fmt.Errorf("remote %s: %w", name, err)
+	}
+	if len(remote.Config().URLs) == 0 {
+		return nil, fmt.Errorf("remote %s has no URL", name)
+	}
+	return remote, nil
+}
+
+// remoteNames returns the names of all remotes, sorted.
+func remoteNames(r *git.Repository) ([]string, error) {
+	remotes, err := r.Remotes()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(remotes))
+	for _, remote := range remotes {
+		names = append(names, remote.Config().Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GitRemote is the configuration of a remote. PushURLs (remote.<name>.pushurl)
+// replace URLs for pushes; pushes go to every push URL, fetches use the first
+// URL.
+type GitRemote struct {
+	Name          string   `json:"name"`
+	URLs          []string `json:"urls"`
+	PushURLs      []string `json:"pushUrls"`
+	FetchRefSpecs []string `json:"fetchRefSpecs"`
+	PushRefSpecs  []string `json:"pushRefSpecs"`
+	// HeadBranch is the remote's default branch as last recorded in
+	// refs/remotes/<name>/HEAD, empty if unknown.
+	HeadBranch string `json:"headBranch"`
+}
+
+// RemoteTestResult describes a remote repository that could be reached.
+type RemoteTestResult struct {
+	URL        string   `json:"url"`
+	HeadBranch string   `json:"headBranch"`
+	Branches   []string `json:"branches"`
+	Empty      bool     `json:"empty"`
+}
+
+// ListRemotes returns the remotes of a repository sorted by name.
+func (a *App) ListRemotes(repoPath string) ([]GitRemote, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := []GitRemote{}
+	for _, rc := range cfg.Remotes {
+		remotes = append(remotes, newGitRemote(r, cfg, rc))
+	}
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	return remotes, nil
+}
+
+// AddRemote adds a remote with the default fetch refspec.
+func (a *App) AddRemote(repoPath string, name string, url string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	name, url = strings.TrimSpace(name), strings.TrimSpace(url)
+	if err := validateRemoteName(name); err != nil {
+		return err
+	}
+	// The config is saved from repoConfig, which keeps the pushurl values of
+	// the other remotes apart from their URLs
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Remotes[name]; ok {
+		return fmt.Errorf("remote %s already exists", name)
+	}
+	rc := &config.RemoteConfig{Name: name, URLs: []string{url}}
+	// Validate sets the default fetch refspec
+	if err := rc.Validate(); err != nil {
+		return err
+	}
+	cfg.Remotes[name] = rc
+	return r.SetConfig(cfg)
+}
+
+// UpdateRemote replaces the URLs and refspecs of an existing remote. Empty
+// fetch refspecs are reset to the default.
+func (a *App) UpdateRemote(repoPath string, remote GitRemote) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+	rc, ok := cfg.Remotes[remote.Name]
+	if !ok {
+		return fmt.Errorf("remote %s does not exist", remote.Name)
+	}
+
+	rc.URLs = trimNonEmpty(remote.URLs)
+	if len(rc.URLs) == 0 {
+		return fmt.Errorf("remote %s needs a URL", remote.Name)
+	}
+	rc.Fetch = nil
+	for _, spec := range trimNonEmpty(remote.FetchRefSpecs) {
+		rc.Fetch = append(rc.Fetch, config.RefSpec(spec))
+	}
+	pushSpecs := trimNonEmpty(remote.PushRefSpecs)
+	for _, spec := range pushSpecs {
+		if err := pushRefSpec(spec).Validate(); err != nil {
+			return fmt.Errorf("invalid push refspec %s: %w", spec, err)
+		}
+	}
+	// Validate checks the fetch refspecs and restores the default if empty
+	if err := rc.Validate(); err != nil {
+		return err
+	}
+
+	raw := cfg.Raw.Section("remote").Subsection(remote.Name)
+	setRawOption(raw, "pushurl", trimNonEmpty(remote.PushURLs))
+	setRawOption(raw, "push", pushSpecs)
+	return r.SetConfig(cfg)
+}
+
+// RemoveRemote removes a remote, its remote-tracking branches and the
+// upstream configuration of branches tracking it, like git remote remove.
+func (a *App) RemoveRemote(repoPath string, name string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Remotes[name]; !ok {
+		return fmt.Errorf("remote %s does not exist", name)
+	}
+
+	delete(cfg.Remotes, name)
+	for branchName, b := range cfg.Branches {
+		if b.Remote == name {
+			b.Remote = ""
+			b.Merge = ""
+		}
+		rawBranch := cfg.Raw.Section("branch").Subsection(branchName)
+		if rawBranch.Option("pushRemote") == name {
+			rawBranch.RemoveOption("pushRemote")
+		}
+	}
+	if cfg.Raw.Section("remote").Option("pushDefault") == name {
+		cfg.Raw.Section("remote").RemoveOption("pushDefault")
+	}
+	if err := r.SetConfig(cfg); err != nil {
+		return err
+	}
+	return moveRemoteRefs(r, name, "")
+}
+
+// RenameRemote renames a remote, rewriting its default fetch refspecs and
+// moving its remote-tracking branches and the branches tracking it, like
+// git remote rename.
+func (a *App) RenameRemote(repoPath string, oldName string, newName string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+	rc, ok := cfg.Remotes[oldName]
+	if !ok {
+		return fmt.Errorf("remote %s does not exist", oldName)
+	}
+	newName = strings.TrimSpace(newName)
+	if err := validateRemoteName(newName); err != nil {
+		return err
+	}
+	if _, exists := cfg.Remotes[newName]; exists {
+		return fmt.Errorf("remote %s already exists", newName)
+	}
+
+	oldPrefix, newPrefix := "refs/remotes/"+oldName+"/", "refs/remotes/"+newName+"/"
+	for i, spec := range rc.Fetch {
+		src, dst, found := strings.Cut(spec.String(), ":")
+		if found && strings.HasPrefix(dst, oldPrefix) {
+			rc.Fetch[i] = config.RefSpec(src + ":" + newPrefix + strings.TrimPrefix(dst, oldPrefix))
+		}
+	}
+	rc.Name = newName
+	delete(cfg.Remotes, oldName)
+	cfg.Remotes[newName] = rc
+
+	for branchName, b := range cfg.Branches {
+		if b.Remote == oldName {
+			b.Remote = newName
+		}
+		rawBranch := cfg.Raw.Section("branch").Subsection(branchName)
+		if rawBranch.Option("pushRemote") == oldName {
+			rawBranch.SetOption("pushRemote", newName)
+		}
+	}
+	if cfg.Raw.Section("remote").Option("pushDefault") == oldName {
+		cfg.Raw.Section("remote").SetOption("pushDefault", newName)
+	}
+	if err := r.SetConfig(cfg); err != nil {
+		return err
+	}
+	return moveRemoteRefs(r, oldName, newName)
+}
+
+// TestRemote connects to a remote and lists its branches and default branch.
+func (a *App) TestRemote(repoPath string, name string) (result *RemoteTestResult, err error) {
+	op := a.startOperation(OperationRemoteList, repoPath)
+	defer func() { err = a.finishOperation(op, err) }()
+
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := resolveRemote(repoPath, r, name, false)
+	if err != nil {
+		return nil, err
+	}
+	return a.listRemote(op.ctx, repoPath, remote.Config().URLs[0])
+}
+
+// TestRemoteURL connects to a URL before it is added as a remote.
+func (a *App) TestRemoteURL(repoPath string, url string) (result *RemoteTestResult, err error) {
+	op := a.startOperation(OperationRemoteList, repoPath)
+	defer func() { err = a.finishOperation(op, err) }()
+
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.listRemote(op.ctx, repoPath, strings.TrimSpace(url))
+}
+
+// UpdateRemoteHead queries the default branch of a remote and records it in
+// refs/remotes/<name>/HEAD, like git remote set-head --auto.
+func (a *App) UpdateRemoteHead(repoPath string, name string) (head string, err error) {
+	op := a.startOperation(OperationRemoteList, repoPath)
+	defer func() { err = a.finishOperation(op, err) }()
+
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := op.ctx.Err(); err != nil {
+		return "", err
+	}
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", err
+	}
+	remote, err := resolveRemote(repoPath, r, name, false)
+	if err != nil {
+		return "", err
+	}
+	result, err := a.listRemote(op.ctx, repoPath, remote.Config().URLs[0])
+	if err != nil {
+		return "", err
+	}
+	if result.HeadBranch == "" {
+		return "", fmt.Errorf("cannot determine the default branch of %s", remote.Config().Name)
+	}
+
+	headRef := plumbing.NewRemoteHEADReferenceName(remote.Config().Name)
+	target := plumbing.NewRemoteReferenceName(remote.Config().Name, result.HeadBranch)
+	if err := r.Storer.SetReference(plumbing.NewSymbolicReference(headRef, target)); err != nil {
+		return "", err
+	}
+	return result.HeadBranch, nil
+}
+
+func (a *App) listRemote(ctx context.Context, repoPath string, url string) (*RemoteTestResult, error) {
+	if url == "" {
+		return nil, errors.New("the remote URL is empty")
+	}
+	auth, err := a.getAuth(ctx, repoPath, url)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+		Status:  fmt.Sprintf("Connecting to %s...", url),
+		Percent: -1,
+	})
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "anonymous", URLs: []string{url}})
+	var refs []*plumbing.Reference
+	err = a.withAuth(ctx, repoPath, url, auth, func(auth transport.AuthMethod) error {
+		refs, err = remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+		return err
+	})
+	result := &RemoteTestResult{URL: url, Branches: []string{}}
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		err = nil
+		result.Empty = true
+	}
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+			Status:  fmt.Sprintf("Connection to %s failed: %v", url, err),
+			Percent: -1,
+		})
+		return nil, err
+	}
+	runtime.EventsEmit(a.ctx, "git-progress", GitProgress{
+		Status:  fmt.Sprintf("Connection to %s completed", url),
+		Percent: 100,
+	})
+	if result.Empty {
+		return result, nil
+	}
+
+	for _, ref := range refs {
+		switch {
+		case ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference:
+			result.HeadBranch = ref.Target().Short()
+		case ref.Name().IsBranch():
+			result.Branches = append(result.Branches, ref.Name().Short())
+		}
+	}
+	sort.Strings(result.Branches)
+	return result, nil
+}
+
+func newGitRemote(r *git.Repository, cfg *config.Config, rc *config.RemoteConfig) GitRemote {
+	raw := cfg.Raw.Section("remote").Subsection(rc.Name)
+	remote := GitRemote{
+		Name:          rc.Name,
+		URLs:          append([]string{}, rc.URLs...),
+		PushURLs:      append([]string{}, raw.Options.GetAll("pushurl")...),
+		FetchRefSpecs: []string{},
+		PushRefSpecs:  append([]string{}, raw.Options.GetAll("push")...),
+	}
+	for _, spec := range rc.Fetch {
+		remote.FetchRefSpecs = append(remote.FetchRefSpecs, spec.String())
+	}
+	if head, err := r.Storer.Reference(plumbing.NewRemoteHEADReferenceName(rc.Name)); err == nil && head.Type() == plumbing.SymbolicReference {
+		remote.HeadBranch = strings.TrimPrefix(head.Target().String(), "refs/remotes/"+rc.Name+"/")
+	}
+	return remote
+}
+
+// pushURLs returns the URLs a push to the remote goes to.
+func pushURLs(cfg *config.Config, remote *git.Remote) []string {
+	raw := cfg.Raw.Section("remote").Subsection(remote.Config().Name)
+	if urls := trimNonEmpty(raw.Options.GetAll("pushurl")); len(urls) > 0 {
+		return urls
+	}
+	return remote.Config().URLs
+}
+
+// pushRefSpecs returns the configured remote.<name>.push refspecs, or the
+// default if there are none.
+func pushRefSpecs(cfg *config.Config, remote *git.Remote) []config.RefSpec {
+	var specs []config.RefSpec
+	raw := cfg.Raw.Section("remote").Subsection(remote.Config().Name)
+	for _, spec := range trimNonEmpty(raw.Options.GetAll("push")) {
+		specs = append(specs, pushRefSpec(spec))
+	}
+	if len(specs) == 0 {
+		specs = []config.RefSpec{config.RefSpec(config.DefaultPushRefSpec)}
+	}
+	return specs
+}
+
+// pushRefSpec completes a push refspec without a destination, e.g.
+// "refs/heads/main", which git accepts but go-git does not.
+func pushRefSpec(spec string) config.RefSpec {
+	if !strings.Contains(spec, ":") {
+		spec = spec + ":" + strings.TrimPrefix(spec, "+")
+	}
+	return config.RefSpec(spec)
+}
+
+// moveRemoteRefs moves the remote-tracking refs of a remote to another
+// remote name, or deletes them if newName is empty.
+func moveRemoteRefs(r *git.Repository, oldName string, newName string) error {
+	refs, err := r.References()
+	if err != nil {
+		return err
+	}
+	prefix := "refs/remotes/" + oldName + "/"
+	var matching []*plumbing.Reference
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), prefix) {
+			matching = append(matching, ref)
+		}
+		return nil
+	})
+
+	for _, ref := range matching {
+		if newName != "" {
+			name := plumbing.ReferenceName("refs/remotes/" + newName + "/" + strings.TrimPrefix(ref.Name().String(), prefix))
+			var moved *plumbing.Reference
+			if ref.Type() == plumbing.SymbolicReference {
+				target := strings.Replace(ref.Target().String(), prefix, "refs/remotes/"+newName+"/", 1)
+				moved = plumbing.NewSymbolicReference(name, plumbing.ReferenceName(target))
+			} else {
+				moved = plumbing.NewHashReference(name, ref.Hash())
+			}
+			if err := r.Storer.SetReference(moved); err != nil {
+				return err
+			}
+		}
+		if err := r.Storer.RemoveReference(ref.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRemoteName(name string) error {
+	if name == "" {
+		return errors.New("the remote name is empty")
+	}
+	if err := plumbing.NewRemoteHEADReferenceName(name).Validate(); err != nil {
+		return fmt.Errorf("'%s' is not a valid remote name", name)
+	}
+	return nil
+}
+
+func setRawOption(s *format.Subsection, key string, values []string) {
+	if len(values) == 0 {
+		s.RemoveOption(key)
+		return
+	}
+	s.SetOption(key, values...)
+}
+
+func trimNonEmpty(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestAddRemoteKeepsPushURLs(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, ".git", "config")
+	f, err := os.OpenFile(configPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString("[remote \"origin\"]\n" +
+		"\turl = https://example.com/fetch.git\n" +
+		"\tpushurl = https://example.com/push.git\n" +
+		"\tfetch = +refs/heads/*:refs/remotes/origin/*\n")
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &App{}
+	if err := a.AddRemote(dir, "team/upstream", "https://example.com/upstream.git"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddRemote(dir, "origin", "https://example.com/other.git"); err == nil {
+		t.Error("adding an existing remote succeeded")
+	}
+
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := r.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin := cfg.Raw.Section("remote").Subsection("origin")
+	if got, want := origin.Options.GetAll("url"), []string{"https://example.com/fetch.git"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("origin urls = %v, want %v", got, want)
+	}
+	if got, want := origin.Options.GetAll("pushurl"), []string{"https://example.com/push.git"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("origin pushurls = %v, want %v", got, want)
+	}
+
+	added, ok := cfg.Remotes["team/upstream"]
+	if !ok {
+		t.Fatal("the remote was not added")
+	}
+	if got, want := added.URLs, []string{"https://example.com/upstream.git"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("added urls = %v, want %v", got, want)
+	}
+	if len(added.Fetch) != 1 || added.Fetch[0] != "+refs/heads/*:refs/remotes/team/upstream/*" {
+		t.Errorf("added fetch refspecs = %v", added.Fetch)
+	}
+}
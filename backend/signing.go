@@ -0,0 +1,318 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// SigningConfig describes how commits and tags are signed in a repository.
+type SigningConfig struct {
+	// Format is gpg.format: openpgp, x509 or ssh.
+	Format string `json:"format"`
+	// Key is user.signingkey (or the profile's signing key). For openpgp it
+	// is a key ID passed to the gpg binary, or the path of an unencrypted
+	// secret key file. For ssh it is the path of a key file or a literal
+	// public key ("key::ssh-ed25519 ...") whose private key is in the agent.
+	Key string `json:"key"`
+	// DefaultKey is set when Key is not configured but derived from the
+	// identity or the default SSH key.
+	DefaultKey bool `json:"defaultKey"`
+	// Program is the signing program, e.g. gpg.program.
+	Program    string `json:"program"`
+	SignCommit bool   `json:"signCommit"`
+	SignTag    bool   `json:"signTag"`
+	// AllowedSignersFile is gpg.ssh.allowedSignersFile, used to verify SSH
+	// signatures.
+	AllowedSignersFile string `json:"allowedSignersFile"`
+}
+
+// GetSigningConfig returns the signing settings of a repository. An empty
+// repoPath returns the global settings.
+func (a *App) GetSigningConfig(repoPath string) (*SigningConfig, error) {
+	if repoPath != "" {
+		mu := getRepoMutex(repoPath)
+		mu.Lock()
+		defer mu.Unlock()
+	}
+	return repoSigningConfig(repoPath)
+}
+
+// repoSigningConfig resolves the signing settings from git config and the
+// repository's identity profile.
+func repoSigningConfig(repoPath string) (*SigningConfig, error) {
+	cfg, err := loadGitConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := matchProfile(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	id := resolveIdentity(cfg, "committer")
+	applyProfile(id, profile)
+
+	sc := &SigningConfig{Format: "openpgp", Key: id.SigningKey}
+	if format, ok := cfg.Get("gpg.format"); ok && format != "" {
+		sc.Format = strings.ToLower(format)
+	}
+	sc.SignCommit = cfg.GetBool("commit.gpgsign", false)
+	sc.SignTag = cfg.GetBool("tag.gpgsign", false)
+
+	sc.Program = signingProgram(cfg, sc.Format)
+	if sc.Format == "ssh" {
+		if f, ok := cfg.Get("gpg.ssh.allowedsignersfile"); ok {
+			sc.AllowedSignersFile = expandHome(f)
+		}
+	}
+
+	if sc.Key == "" {
+		sc.DefaultKey = true
+		if sc.Format == "ssh" {
+			// Without a configured key, sign with the profile's SSH key or
+			// the default key from ~/.ssh.
+			if profile != nil && profile.SSHKeyPath != "" {
+				sc.Key = profile.SSHKeyPath
+			} else if info, err := defaultSshKey(); err == nil && info.HasKey {
+				sc.Key = info.Path
+			}
+		} else if id.Name != "" && id.Email != "" {
+			// Like git, fall back to the committer identity to select the key.
+			sc.Key = fmt.Sprintf("%s <%s>", id.Name, id.Email)
+		}
+	}
+	return sc, nil
+}
+
+// signingProgram returns the program used to sign and verify signatures of a
+// format, honouring gpg.program and gpg.<format>.program.
+func signingProgram(cfg *gitConfig, format string) string {
+	program := map[string]string{"openpgp": "gpg", "x509": "gpgsm", "ssh": "ssh-keygen"}[format]
+	if format == "openpgp" {
+		if p, ok := cfg.Get("gpg.program"); ok && p != "" {
+			program = p
+		}
+	}
+	if p, ok := cfg.Get("gpg." + format + ".program"); ok && p != "" {
+		program = p
+	}
+	return program
+}
+
+// signer returns a go-git signer for the configuration. The signing
+// programs are stopped when ctx is cancelled.
+func (sc *SigningConfig) signer(ctx context.Context) (git.Signer, error) {
+	if sc.Key == "" {
+		return nil, errors.New("signing failed: no signing key configured, set user.signingkey")
+	}
+	switch sc.Format {
+	case "openpgp":
+		if _, err := os.Stat(expandHome(sc.Key)); err == nil {
+			return loadKeyFileSigner(expandHome(sc.Key))
+		}
+		return &gpgProgramSigner{ctx: ctx, program: sc.Program, key: sc.Key}, nil
+	case "x509":
+		return &gpgProgramSigner{ctx: ctx, program: sc.Program, key: sc.Key}, nil
+	case "ssh":
+		return &sshSigner{ctx: ctx, program: sc.Program, key: sc.Key}, nil
+	}
+	return nil, fmt.Errorf("signing failed: unsupported gpg.format %s", sc.Format)
+}
+
+// keyFileSigner signs with a secret key read from a key file.
+type keyFileSigner struct {
+	entity *openpgp.Entity
+}
+
+func loadKeyFileSigner(path string) (*keyFileSigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("signing failed: %w", err)
+	}
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing failed: cannot read key file %s: %w", path, err)
+	}
+	for _, e := range entities {
+		if e.PrivateKey == nil {
+			continue
+		}
+		if e.PrivateKey.Encrypted {
+			return nil, fmt.Errorf("signing failed: the key in %s is protected by a passphrase, use a key ID to sign with gpg instead", path)
+		}
+		return &keyFileSigner{entity: e}, nil
+	}
+	return nil, fmt.Errorf("signing failed: %s does not contain a secret key", path)
+}
+
+func (s *keyFileSigner) Sign(message io.Reader) ([]byte, error) {
+	var b bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&b, s.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("signing failed: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// gpgProgramSigner signs by running gpg (or gpgsm) the way git does, so keys
+// on smartcards or protected by gpg-agent work.
+type gpgProgramSigner struct {
+	ctx     context.Context
+	program string
+	key     string
+}
+
+func (s *gpgProgramSigner) Sign(message io.Reader) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(s.ctx, s.program, "--status-fd=2", "-bsau", s.key)
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = message
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil || !strings.Contains(stderr.String(), "[GNUPG:] SIG_CREATED ") {
+		return nil, fmt.Errorf("%s failed to sign the data: %s", s.program, gpgErrorMessage(err, stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// gpgErrorMessage extracts the human readable part of gpg's output, dropping
+// the status lines.
+func gpgErrorMessage(err error, stderr string) string {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "[GNUPG:]") {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > 0 {
+		return strings.Join(lines, "; ")
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "no signature was created"
+}
+
+// sshSigner signs with ssh-keygen -Y sign in the "git" namespace, like git
+// with gpg.format=ssh.
+type sshSigner struct {
+	ctx     context.Context
+	program string
+	key     string
+}
+
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	args := []string{"-Y", "sign", "-n", "git"}
+
+	literal, isLiteral := literalSshKey(s.key)
+	if isLiteral {
+		// A literal public key: ssh-keygen takes the private key from the
+		// agent and needs the public key in a file.
+		f, err := os.CreateTemp("", ".git_signing_key_tmp")
+		if err != nil {
+			return nil, fmt.Errorf("signing failed: %w", err)
+		}
+		defer func() { _ = os.Remove(f.Name()) }()
+		if _, err := f.WriteString(literal + "\n"); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("signing failed: %w", err)
+		}
+		_ = f.Close()
+		args = append(args, "-U", "-f", f.Name())
+	} else {
+		args = append(args, "-f", expandHome(s.key))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(s.ctx, s.program, args...)
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = message
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		if strings.Contains(msg, "passphrase") {
+			msg += " (load the key into ssh-agent to sign with a protected key)"
+		}
+		return nil, fmt.Errorf("ssh-keygen failed to sign the data: %s", msg)
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("-----BEGIN SSH SIGNATURE-----")) {
+		return nil, errors.New("ssh-keygen failed to sign the data: no signature was created")
+	}
+	return stdout.Bytes(), nil
+}
+
+// literalSshKey reports whether a signing key is a public key rather than a
+// path, returning the key without the "key::" prefix.
+func literalSshKey(key string) (string, bool) {
+	if strings.HasPrefix(key, "key::") {
+		return strings.TrimPrefix(key, "key::"), true
+	}
+	if strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "ecdsa-") || strings.HasPrefix(key, "sk-") {
+		return key, true
+	}
+	return "", false
+}
+
+// createSignedTag creates an annotated tag signed with signer.
+func createSignedTag(r *git.Repository, name string, target plumbing.Hash, message string, tagger *object.Signature, signer git.Signer) error {
+	refName := plumbing.NewTagReferenceName(name)
+	if err := refName.Validate(); err != nil {
+		return err
+	}
+	if _, err := r.Reference(refName, false); err == nil {
+		return git.ErrTagExists
+	}
+
+	targetObj, err := r.Storer.EncodedObject(plumbing.AnyObject, target)
+	if err != nil {
+		return err
+	}
+
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *tagger,
+		Message:    strings.TrimSpace(message) + "\n",
+		TargetType: targetObj.Type(),
+		Target:     target,
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(encoded); err != nil {
+		return err
+	}
+	reader, err := encoded.Reader()
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(reader)
+	if err != nil {
+		return err
+	}
+	tag.PGPSignature = string(sig)
+
+	hash, err := storeObject(r, tag)
+	if err != nil {
+		return err
+	}
+	return r.Storer.SetReference(plumbing.NewHashReference(refName, hash))
+}
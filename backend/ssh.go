@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
 // SshKeyInfo information about SSH keys for Git authentication.
@@ -16,6 +17,12 @@ type SshKeyInfo struct {
 
 // GetSshKeyInfo returns information about the user's SSH key.
 func (a *App) GetSshKeyInfo() (SshKeyInfo, error) {
+	return defaultSshKey()
+}
+
+// defaultSshKey returns the key used for authentication and SSH signing when
+// nothing else is configured: id_ed25519, or id_rsa as a fallback.
+func defaultSshKey() (SshKeyInfo, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return SshKeyInfo{}, err
@@ -79,3 +86,77 @@ func (a *App) GenerateSshKey() (SshKeyInfo, error) {
 
 	return a.GetSshKeyInfo()
 }
+
+// ListSshKeys returns all key pairs in ~/.ssh, e.g. to pick a signing key.
+func (a *App) ListSshKeys() ([]SshKeyInfo, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	pubKeys, err := filepath.Glob(filepath.Join(home, ".ssh", "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []SshKeyInfo{}
+	for _, pubPath := range pubKeys {
+		pubData, err := os.ReadFile(pubPath)
+		if err != nil {
+			continue
+		}
+		keyPath := strings.TrimSuffix(pubPath, ".pub")
+		_, statErr := os.Stat(keyPath)
+		keys = append(keys, SshKeyInfo{
+			PublicKey: strings.TrimSpace(string(pubData)),
+			HasKey:    statErr == nil,
+			Path:      keyPath,
+		})
+	}
+	return keys, nil
+}
+
+// AddAllowedSigner adds a public key to the gpg.ssh.allowedSignersFile of a
+// repository (or the global one for an empty repoPath), so signatures made
+// with it verify as good. Keys already listed for the principal are skipped.
+func (a *App) AddAllowedSigner(repoPath string, principal string, publicKey string) error {
+	if repoPath != "" {
+		mu := getRepoMutex(repoPath)
+		mu.Lock()
+		defer mu.Unlock()
+	}
+
+	sc, err := repoSigningConfig(repoPath)
+	if err != nil {
+		return err
+	}
+	if sc.AllowedSignersFile == "" {
+		return fmt.Errorf("gpg.ssh.allowedSignersFile is not configured")
+	}
+
+	fields := strings.Fields(publicKey)
+	if len(fields) < 2 || principal == "" || strings.ContainsAny(principal, " \t") {
+		return fmt.Errorf("invalid allowed signer: expected a principal and a public key")
+	}
+	entry := fmt.Sprintf("%s namespaces=\"git\" %s %s", principal, fields[0], fields[1])
+
+	existing, err := os.ReadFile(sc.AllowedSignersFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		f := strings.Fields(line)
+		if len(f) >= 3 && f[0] == principal && strings.Contains(line, fields[1]) {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(sc.AllowedSignersFile), 0700); err != nil {
+		return err
+	}
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return os.WriteFile(sc.AllowedSignersFile, []byte(content+entry+"\n"), 0644)
+}
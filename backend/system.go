@@ -60,3 +60,50 @@ func (a *App) OpenInFileManager(path string) error {
 func (a *App) OpenInBrowser(url string) {
 	runtime.BrowserOpenURL(a.ctx, url)
 }
+
+// SelectSaveFile opens a save file dialog and returns the chosen path.
+func (a *App) SelectSaveFile(title string, defaultFilename string) (string, error) {
+	return runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           title,
+		DefaultFilename: defaultFilename,
+	})
+}
+
+// repoSettingsKey identifies a repository in the app's per-repository
+// settings files.
+func repoSettingsKey(repoPath string) string {
+	if abs, err := filepath.Abs(repoPath); err == nil {
+		repoPath = abs
+	}
+	return filepath.ToSlash(filepath.Clean(repoPath))
+}
+
+// gitShell returns the shell that runs hooks and "!" credential helpers. On
+// Windows it is the sh of Git for Windows, which is usually not on the PATH:
+// git.exe is in cmd\ or mingw64\bin\ of the installation and sh.exe in bin\
+// or usr\bin\.
+func gitShell() (string, error) {
+	if goruntime.GOOS != "windows" {
+		return "sh", nil
+	}
+	if git, err := exec.LookPath("git"); err == nil {
+		if resolved, err := filepath.EvalSymlinks(git); err == nil {
+			git = resolved
+		}
+		dir := filepath.Dir(git)
+		for _, root := range []string{filepath.Dir(dir), filepath.Dir(filepath.Dir(dir))} {
+			for _, sh := range []string{
+				filepath.Join(root, "usr", "bin", "sh.exe"),
+				filepath.Join(root, "bin", "sh.exe"),
+			} {
+				if info, err := os.Stat(sh); err == nil && !info.IsDir() {
+					return sh, nil
+				}
+			}
+		}
+	}
+	if sh, err := exec.LookPath("sh"); err == nil {
+		return sh, nil
+	}
+	return "", fmt.Errorf("cannot find the sh of Git for Windows")
+}
@@ -0,0 +1,354 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Branch tracking modes for BranchOptions.Track.
+const (
+	// TrackAuto follows branch.autoSetupMerge: by default a branch tracks
+	// its start point only when that is a remote-tracking branch. With
+	// inherit it takes the upstream of the start branch, with simple it
+	// only tracks a remote branch of the same name.
+	TrackAuto = ""
+	// TrackAlways tracks the start point even when it is a local branch.
+	TrackAlways = "always"
+	// TrackNever never configures an upstream.
+	TrackNever = "never"
+)
+
+// BranchOptions describes a branch to create. An empty StartPoint means HEAD;
+// otherwise it is a local branch, a remote-tracking branch such as
+// origin/main, a tag or any other revision.
+type BranchOptions struct {
+	Name       string `json:"name"`
+	StartPoint string `json:"startPoint"`
+	Checkout   bool   `json:"checkout"`
+	Track      string `json:"track"`
+}
+
+// BranchTracking is the upstream of a local branch and how far the two have
+// diverged. Upstream is the short name of the tracked ref (origin/main, or a
+// local branch name). Gone is set when the upstream is configured but its
+// ref no longer exists, e.g. after the remote branch was deleted and pruned.
+type BranchTracking struct {
+	Branch   string `json:"branch"`
+	Upstream string `json:"upstream"`
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	Gone     bool   `json:"gone"`
+}
+
+// CreateBranchWithOptions creates a branch at the given start point and sets
+// up its upstream according to opts.Track.
+func (a *App) CreateBranchWithOptions(repoPath string, opts BranchOptions) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+
+	branchName := plumbing.NewBranchReferenceName(opts.Name)
+	if err := branchName.Validate(); err != nil {
+		return fmt.Errorf("invalid branch name %q", opts.Name)
+	}
+	if _, err := r.Reference(branchName, false); err == nil {
+		return fmt.Errorf("a branch named %s already exists", opts.Name)
+	}
+
+	hash, startRef, err := resolveStartPoint(r, opts.StartPoint)
+	if err != nil {
+		return err
+	}
+
+	if opts.Checkout {
+		w, err := r.Worktree()
+		if err != nil {
+			return err
+		}
+		err = w.Checkout(&git.CheckoutOptions{
+			Branch: branchName,
+			Create: true,
+			Hash:   hash,
+		})
+		if err != nil {
+			return err
+		}
+	} else if err := r.Storer.SetReference(plumbing.NewHashReference(branchName, hash)); err != nil {
+		return err
+	}
+
+	return setupTracking(repoPath, r, opts.Name, startRef, opts.Track)
+}
+
+// SetUpstream sets the upstream of a local branch to a remote-tracking branch
+// (origin/main) or a local branch. An empty upstream unsets it.
+func (a *App) SetUpstream(repoPath string, branch string, upstream string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.Reference(plumbing.NewBranchReferenceName(branch), false); err != nil {
+		return fmt.Errorf("branch %s: %w", branch, err)
+	}
+
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+
+	if upstream == "" {
+		if b, ok := cfg.Branches[branch]; ok {
+			b.Remote = ""
+			b.Merge = ""
+			// Drop the section entirely unless it holds other options
+			keep := false
+			for _, o := range cfg.Raw.Section("branch").Subsection(branch).Options {
+				if !o.IsKey("remote") && !o.IsKey("merge") {
+					keep = true
+				}
+			}
+			if !keep {
+				delete(cfg.Branches, branch)
+			}
+		}
+		return r.SetConfig(cfg)
+	}
+
+	remote, merge := "", plumbing.ReferenceName("")
+	if ref, err := r.Reference(plumbing.NewBranchReferenceName(upstream), false); err == nil {
+		if upstream == branch {
+			return fmt.Errorf("branch %s cannot be its own upstream", branch)
+		}
+		remote, merge = ".", ref.Name()
+	} else if ref, err := r.Reference(plumbing.ReferenceName("refs/remotes/"+upstream), false); err == nil {
+		remote, merge = remoteTrackingSource(cfg, ref.Name())
+		if remote == "" {
+			return fmt.Errorf("%s is not fetched by any configured remote", upstream)
+		}
+	} else {
+		return fmt.Errorf("no remote-tracking or local branch named %s", upstream)
+	}
+
+	setBranchUpstream(cfg, branch, remote, merge)
+	return r.SetConfig(cfg)
+}
+
+// GetBranchTracking returns the upstream and ahead/behind counts of every
+// local branch, sorted by name.
+func (a *App) GetBranchTracking(repoPath string) ([]BranchTracking, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return branchTracking(repoPath, r)
+}
+
+// branchTracking computes the tracking state of all local branches. The caller
+// must hold the repository mutex.
+func branchTracking(repoPath string, r *git.Repository) ([]BranchTracking, error) {
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := loadHistoryIndex(repoPath, r)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := r.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []BranchTracking
+	err = branches.ForEach(func(ref *plumbing.Reference) error {
+		t := BranchTracking{Branch: ref.Name().Short()}
+		b, ok := cfg.Branches[t.Branch]
+		if !ok || b.Remote == "" || b.Merge == "" {
+			result = append(result, t)
+			return nil
+		}
+
+		tracked := trackingRef(cfg, b.Remote, b.Merge)
+		if tracked == "" {
+			t.Upstream = b.Remote + "/" + b.Merge.Short()
+			t.Gone = true
+			result = append(result, t)
+			return nil
+		}
+		t.Upstream = tracked.Short()
+
+		upstream, err := r.Reference(tracked, true)
+		if err != nil {
+			t.Gone = true
+		} else {
+			t.Ahead, t.Behind = idx.aheadBehind(ref.Hash(), upstream.Hash())
+		}
+		result = append(result, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Branch < result[j].Branch })
+	return result, nil
+}
+
+// resolveStartPoint resolves a branch start point to a commit, preferring
+// local branches over remote-tracking branches like git does. The returned
+// ref is the branch the start point names, or "" for other revisions.
+func resolveStartPoint(r *git.Repository, startPoint string) (plumbing.Hash, plumbing.ReferenceName, error) {
+	if startPoint == "" || startPoint == "HEAD" {
+		head, err := r.Head()
+		if err != nil {
+			return plumbing.ZeroHash, "", err
+		}
+		if head.Name().IsBranch() {
+			return head.Hash(), head.Name(), nil
+		}
+		return head.Hash(), "", nil
+	}
+
+	for _, name := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(startPoint),
+		plumbing.ReferenceName("refs/remotes/" + startPoint),
+	} {
+		if ref, err := r.Reference(name, true); err == nil {
+			return ref.Hash(), name, nil
+		}
+	}
+
+	h, err := r.ResolveRevision(plumbing.Revision(startPoint))
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("start point %s: %w", startPoint, err)
+	}
+	return *h, "", nil
+}
+
+// setupTracking configures the upstream of a newly created branch that was
+// started from startRef.
+func setupTracking(repoPath string, r *git.Repository, branch string, startRef plumbing.ReferenceName, track string) error {
+	if track == TrackNever || startRef == "" {
+		return nil
+	}
+
+	cfg, err := repoConfig(r)
+	if err != nil {
+		return err
+	}
+
+	inherit, simple := false, false
+	if track == TrackAuto {
+		gc, err := loadGitConfig(repoPath)
+		if err != nil {
+			return err
+		}
+		value, _ := gc.Get("branch.autosetupmerge")
+		switch strings.ToLower(value) {
+		case "false":
+			return nil
+		case "always":
+			track = TrackAlways
+		case "inherit":
+			inherit = true
+		case "simple":
+			simple = true
+		}
+	}
+
+	remote, merge := "", plumbing.ReferenceName("")
+	switch {
+	case inherit:
+		if b, ok := cfg.Branches[startRef.Short()]; ok && startRef.IsBranch() {
+			remote, merge = b.Remote, b.Merge
+		}
+	case startRef.IsRemote():
+		remote, merge = remoteTrackingSource(cfg, startRef)
+		if simple && merge.Short() != branch {
+			return nil
+		}
+	case startRef.IsBranch() && track == TrackAlways:
+		remote, merge = ".", startRef
+	}
+	if remote == "" || merge == "" {
+		return nil
+	}
+
+	setBranchUpstream(cfg, branch, remote, merge)
+	return r.SetConfig(cfg)
+}
+
+// setBranchUpstream sets branch.<name>.remote and branch.<name>.merge,
+// keeping any other options of the branch.
+func setBranchUpstream(cfg *config.Config, branch string, remote string, merge plumbing.ReferenceName) {
+	b, ok := cfg.Branches[branch]
+	if !ok {
+		b = &config.Branch{Name: branch}
+		cfg.Branches[branch] = b
+	}
+	b.Remote = remote
+	b.Merge = merge
+}
+
+// trackingRef maps the upstream of a branch to the local ref that tracks it,
+// using the remote's fetch refspecs. Local upstreams (remote ".") track the
+// branch itself. It returns "" if no refspec fetches the merge ref.
+func trackingRef(cfg *config.Config, remote string, merge plumbing.ReferenceName) plumbing.ReferenceName {
+	if remote == "." {
+		return merge
+	}
+	rc, ok := cfg.Remotes[remote]
+	if !ok {
+		return ""
+	}
+	for _, spec := range rc.Fetch {
+		if spec.Match(merge) {
+			return spec.Dst(merge)
+		}
+	}
+	return ""
+}
+
+// remoteTrackingSource is the reverse of trackingRef: it finds the remote and
+// the remote branch a remote-tracking ref is fetched from.
+func remoteTrackingSource(cfg *config.Config, ref plumbing.ReferenceName) (string, plumbing.ReferenceName) {
+	names := make([]string, 0, len(cfg.Remotes))
+	for name := range cfg.Remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, spec := range cfg.Remotes[name].Fetch {
+			reverse := config.RefSpec(strings.TrimPrefix(spec.String(), "+")).Reverse()
+			if !reverse.Match(ref) {
+				continue
+			}
+			if merge := reverse.Dst(ref); merge.IsBranch() {
+				return name, merge
+			}
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,148 @@
+package backend
+
+import (
+	"regexp"
+	"strings"
+)
+
+type CommitTrailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+var trailerLineRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)[ \t]*:[ \t]*(.*)$`)
+
+// canonicalTrailerKeys maps well-known trailer keys (lower-cased) to the
+// spelling git and most tooling use.
+var canonicalTrailerKeys = map[string]string{
+	"signed-off-by":  "Signed-off-by",
+	"co-authored-by": "Co-authored-by",
+	"reviewed-by":    "Reviewed-by",
+	"acked-by":       "Acked-by",
+	"tested-by":      "Tested-by",
+	"reported-by":    "Reported-by",
+	"suggested-by":   "Suggested-by",
+	"helped-by":      "Helped-by",
+	"fixes":          "Fixes",
+	"closes":         "Closes",
+	"refs":           "Refs",
+	"change-id":      "Change-Id",
+	"bug":            "Bug",
+	"cc":             "Cc",
+}
+
+// canonicalTrailerKey normalises the spelling of well-known trailer keys and
+// leaves custom keys untouched.
+func canonicalTrailerKey(key string) string {
+	key = strings.TrimSpace(key)
+	if canonical, ok := canonicalTrailerKeys[strings.ToLower(key)]; ok {
+		return canonical
+	}
+	return key
+}
+
+// parseTrailers extracts the trailers from the last paragraph of a commit
+// message, following the rules of git interpret-trailers: the paragraph must
+// not be the subject, and either consist only of trailers (and their
+// continuation lines), or contain at least 25% trailers including one
+// generated by git itself.
+func parseTrailers(message string) []CommitTrailer {
+	trailers, _ := findTrailerBlock(message)
+	return trailers
+}
+
+// findTrailerBlock returns the trailers of the message and the index of the
+// line the trailer block starts at, or -1 if the message has none.
+func findTrailerBlock(message string) ([]CommitTrailer, int) {
+	lines := strings.Split(strings.TrimRight(message, " \t\n"), "\n")
+
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	// The first paragraph is the subject, never a trailer block.
+	hasSubject := false
+	for _, line := range lines[:start] {
+		if strings.TrimSpace(line) != "" {
+			hasSubject = true
+			break
+		}
+	}
+	if !hasSubject || start == len(lines) {
+		return []CommitTrailer{}, -1
+	}
+
+	trailers := []CommitTrailer{}
+	other := 0
+	gitGenerated := false
+	for _, line := range lines[start:] {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(trailers) > 0 {
+			last := &trailers[len(trailers)-1]
+			last.Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		if strings.HasPrefix(line, "(cherry picked from commit ") {
+			gitGenerated = true
+			other++
+			continue
+		}
+		m := trailerLineRegex.FindStringSubmatch(line)
+		if m == nil {
+			other++
+			continue
+		}
+		key := canonicalTrailerKey(m[1])
+		if key == "Signed-off-by" {
+			gitGenerated = true
+		}
+		trailers = append(trailers, CommitTrailer{Key: key, Value: strings.TrimSpace(m[2])})
+	}
+
+	if len(trailers) == 0 {
+		return []CommitTrailer{}, -1
+	}
+	if other > 0 && (!gitGenerated || len(trailers)*4 < len(trailers)+other) {
+		return []CommitTrailer{}, -1
+	}
+	return trailers, start
+}
+
+// appendTrailers adds trailers to a commit message in canonical "Key: value"
+// form. They are appended to an existing trailer block, or to a new paragraph
+// if the message has none. Trailers already present with the same value are
+// not duplicated.
+func appendTrailers(message string, trailers []CommitTrailer) string {
+	message = strings.TrimRight(message, " \t\n")
+	existing, start := findTrailerBlock(message)
+
+	var lines []string
+	for _, t := range trailers {
+		t.Key = canonicalTrailerKey(t.Key)
+		t.Value = strings.TrimSpace(t.Value)
+		if t.Key == "" || t.Value == "" {
+			continue
+		}
+
+		duplicate := false
+		for _, e := range existing {
+			if strings.EqualFold(e.Key, t.Key) && e.Value == t.Value {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		existing = append(existing, t)
+		lines = append(lines, t.Key+": "+t.Value)
+	}
+
+	if len(lines) == 0 {
+		return message
+	}
+	if start >= 0 {
+		return message + "\n" + strings.Join(lines, "\n")
+	}
+	return message + "\n\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,219 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type TreeEntry struct {
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	Mode         string `json:"mode"`
+	Type         string `json:"type"` // tree, blob, symlink, submodule
+	Size         int64  `json:"size"`
+	Hash         string `json:"hash"`
+	SubmoduleURL string `json:"submoduleUrl"`
+}
+
+type FileAtRevision struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	IsBinary bool   `json:"isBinary"`
+	Content  string `json:"content"`
+}
+
+// GetTreeAtRevision lists the entries of a directory as it was at the given
+// revision. An empty dirPath lists the repository root, an empty rev uses HEAD.
+func (a *App) GetTreeAtRevision(repoPath string, rev string, dirPath string) ([]TreeEntry, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := resolveCommit(r, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	tree := root
+	if dirPath != "" {
+		tree, err = root.Tree(dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("directory %s not found at %s: %w", dirPath, rev, err)
+		}
+	}
+
+	var modules *config.Modules
+	result := []TreeEntry{}
+	for _, e := range tree.Entries {
+		entry := TreeEntry{
+			Name: e.Name,
+			Path: path.Join(dirPath, e.Name),
+			Mode: fmt.Sprintf("%06o", uint32(e.Mode)),
+			Hash: e.Hash.String(),
+		}
+
+		switch e.Mode {
+		case filemode.Dir:
+			entry.Type = "tree"
+		case filemode.Submodule:
+			entry.Type = "submodule"
+			if modules == nil {
+				modules = readSubmodules(root)
+			}
+			for _, m := range modules.Submodules {
+				if m.Path == entry.Path {
+					entry.SubmoduleURL = m.URL
+					break
+				}
+			}
+		case filemode.Symlink:
+			entry.Type = "symlink"
+			entry.Size, _ = tree.Size(e.Name)
+		default:
+			entry.Type = "blob"
+			entry.Size, _ = tree.Size(e.Name)
+		}
+
+		result = append(result, entry)
+	}
+
+	// Directories first, then files, both alphabetically
+	sort.SliceStable(result, func(i, j int) bool {
+		if (result[i].Type == "tree") != (result[j].Type == "tree") {
+			return result[i].Type == "tree"
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// GetFileAtRevision returns the contents of a file as it was at the given
+// revision. The content of binary files is left empty.
+func (a *App) GetFileAtRevision(repoPath string, rev string, filePath string) (*FileAtRevision, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fileAtRevision(r, rev, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FileAtRevision{
+		Path: filePath,
+		Size: file.Size,
+	}
+
+	result.IsBinary, err = file.IsBinary()
+	if err != nil {
+		return nil, err
+	}
+	if !result.IsBinary {
+		result.Content, err = file.Contents()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// SaveFileAtRevision writes a file as it was at the given revision to destPath.
+func (a *App) SaveFileAtRevision(repoPath string, rev string, filePath string, destPath string) error {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+
+	file, err := fileAtRevision(r, rev, filePath)
+	if err != nil {
+		return err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	out, err := os.Create(filepath.Clean(destPath))
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", destPath, err)
+	}
+
+	_, err = out.ReadFrom(reader)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// resolveCommit resolves a revision (hash, branch, tag, HEAD~2, ...) to a
+// commit. An empty revision resolves to HEAD.
+func resolveCommit(r *git.Repository, rev string) (*object.Commit, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	h, err := r.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", rev, err)
+	}
+	return r.CommitObject(*h)
+}
+
+func fileAtRevision(r *git.Repository, rev string, filePath string) (*object.File, error) {
+	commit, err := resolveCommit(r, rev)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("file %s not found at %s: %w", filePath, rev, err)
+	}
+	return file, nil
+}
+
+// readSubmodules parses .gitmodules from the given root tree. A missing or
+// invalid file yields an empty set of submodules.
+func readSubmodules(root *object.Tree) *config.Modules {
+	modules := config.NewModules()
+	file, err := root.File(".gitmodules")
+	if err != nil {
+		return modules
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return modules
+	}
+	_ = modules.Unmarshal([]byte(content))
+	return modules
+}
@@ -0,0 +1,369 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Signature verification results.
+const (
+	SignatureUnsigned   = "unsigned"
+	SignatureGood       = "good"
+	SignatureBad        = "bad"
+	SignatureUnknownKey = "unknown-key"
+	SignatureExpired    = "expired"
+	SignatureRevoked    = "revoked"
+	SignatureError      = "error"
+	// SignatureUnverified is a signature that was not verified yet, see
+	// VerifyCommitSignature.
+	SignatureUnverified = "unverified"
+)
+
+// SignatureInfo is the verification result of a commit or tag signature.
+// Trusted is set for good signatures from keys the user trusts: fully or
+// ultimately trusted OpenPGP keys, or SSH keys in the allowed signers file.
+type SignatureInfo struct {
+	Status      string `json:"status"`
+	Format      string `json:"format"`
+	Signer      string `json:"signer"`
+	KeyID       string `json:"keyId"`
+	Fingerprint string `json:"fingerprint"`
+	Trusted     bool   `json:"trusted"`
+	Message     string `json:"message"`
+}
+
+// signatureCache holds verification results by object hash. Objects never
+// change, but keyrings do: VerifyCommitSignature refreshes an entry.
+var signatureCache sync.Map
+
+// maxVerifyWorkers bounds the gpg/ssh-keygen processes run in parallel.
+const maxVerifyWorkers = 8
+
+// maxVerifiedCommits is the number of commits GetCommitHistory verifies, a
+// page of the history view.
+const maxVerifiedCommits = 100
+
+// VerifyCommitSignature verifies the signature of a single commit, bypassing
+// the cache, e.g. after importing a key.
+func (a *App) VerifyCommitSignature(repoPath string, commitHash string) (*SignatureInfo, error) {
+	mu := getRepoMutex(repoPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	c, err := r.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return nil, err
+	}
+	v, err := newSignatureVerifier(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureCache.Delete(c.Hash)
+	info := v.verifyCommits([]*object.Commit{c})[0]
+	return &info, nil
+}
+
+// signatureVerifier verifies signatures with the programs git would use.
+type signatureVerifier struct {
+	gpgProgram     string
+	x509Program    string
+	sshProgram     string
+	allowedSigners string
+}
+
+func newSignatureVerifier(repoPath string) (*signatureVerifier, error) {
+	cfg, err := loadGitConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	v := &signatureVerifier{
+		gpgProgram:  signingProgram(cfg, "openpgp"),
+		x509Program: signingProgram(cfg, "x509"),
+		sshProgram:  signingProgram(cfg, "ssh"),
+	}
+	if f, ok := cfg.Get("gpg.ssh.allowedsignersfile"); ok && f != "" {
+		v.allowedSigners = expandHome(f)
+	}
+	return v, nil
+}
+
+// verifyCommits verifies commit signatures, using cached results where
+// possible.
+func (v *signatureVerifier) verifyCommits(commits []*object.Commit) []SignatureInfo {
+	return v.verifyAll(len(commits), func(i int) (plumbing.Hash, string, signableObject, object.Signature) {
+		c := commits[i]
+		return c.Hash, c.PGPSignature, c, c.Committer
+	})
+}
+
+// verifyTags verifies annotated tag signatures, using cached results where
+// possible.
+func (v *signatureVerifier) verifyTags(tags []*object.Tag) []SignatureInfo {
+	return v.verifyAll(len(tags), func(i int) (plumbing.Hash, string, signableObject, object.Signature) {
+		t := tags[i]
+		return t.Hash, t.PGPSignature, t, t.Tagger
+	})
+}
+
+type signableObject interface {
+	EncodeWithoutSignature(o plumbing.EncodedObject) error
+}
+
+func (v *signatureVerifier) verifyAll(n int, get func(i int) (plumbing.Hash, string, signableObject, object.Signature)) []SignatureInfo {
+	results := make([]SignatureInfo, n)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	workers := maxVerifyWorkers
+	if n < workers {
+		workers = n
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				hash, sig, obj, signer := get(i)
+				results[i] = v.verifyObject(hash, sig, obj, signer)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		hash, sig, _, _ := get(i)
+		if info := cachedSignature(hash, sig); info.Status != SignatureUnverified {
+			results[i] = info
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// cachedSignature returns the verification result of an object without
+// verifying it: the cached one, or SignatureUnverified.
+func cachedSignature(hash plumbing.Hash, signature string) SignatureInfo {
+	if signature == "" {
+		return SignatureInfo{Status: SignatureUnsigned}
+	}
+	if cached, ok := signatureCache.Load(hash); ok {
+		return cached.(SignatureInfo)
+	}
+	return SignatureInfo{Status: SignatureUnverified}
+}
+
+func (v *signatureVerifier) verifyObject(hash plumbing.Hash, signature string, obj signableObject, signer object.Signature) SignatureInfo {
+	encoded := &plumbing.MemoryObject{}
+	if err := obj.EncodeWithoutSignature(encoded); err != nil {
+		return SignatureInfo{Status: SignatureError, Message: err.Error()}
+	}
+	reader, err := encoded.Reader()
+	if err != nil {
+		return SignatureInfo{Status: SignatureError, Message: err.Error()}
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return SignatureInfo{Status: SignatureError, Message: err.Error()}
+	}
+
+	var info SignatureInfo
+	switch {
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		info = v.verifySSH(signature, payload, signer)
+	case strings.HasPrefix(signature, "-----BEGIN SIGNED MESSAGE-----"),
+		strings.HasPrefix(signature, "-----BEGIN CERTIFICATE-----"):
+		info = verifyGPG(v.x509Program, signature, payload)
+		info.Format = "x509"
+	default:
+		info = verifyGPG(v.gpgProgram, signature, payload)
+		info.Format = "openpgp"
+	}
+
+	// Failures to run the verifier are not cached, they may be transient.
+	if info.Status != SignatureError {
+		signatureCache.Store(hash, info)
+	}
+	return info
+}
+
+// verifyGPG verifies an OpenPGP or X.509 signature with gpg/gpgsm and reads
+// the result from its status output, like git does.
+func verifyGPG(program string, signature string, payload []byte) SignatureInfo {
+	sigFile, err := writeTempSignature(signature)
+	if err != nil {
+		return SignatureInfo{Status: SignatureError, Message: err.Error()}
+	}
+	defer func() { _ = os.Remove(sigFile) }()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(program, "--keyid-format=long", "--status-fd=1", "--verify", sigFile, "-")
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	info := parseGPGStatus(stdout.String())
+	if info.Status == "" {
+		info.Status = SignatureError
+		info.Message = gpgErrorMessage(runErr, stderr.String())
+		if errors.Is(runErr, exec.ErrNotFound) {
+			info.Message = fmt.Sprintf("%s is not installed", program)
+		}
+	}
+	return info
+}
+
+// parseGPGStatus interprets the [GNUPG:] status lines of gpg --verify.
+func parseGPGStatus(status string) SignatureInfo {
+	var info SignatureInfo
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(line, "[GNUPG:] "))
+		if len(fields) == 0 || !strings.HasPrefix(line, "[GNUPG:] ") {
+			continue
+		}
+
+		// The uid is the rest of the line after the key ID.
+		uid := ""
+		if len(fields) > 2 {
+			uid = strings.Join(fields[2:], " ")
+		}
+
+		switch fields[0] {
+		case "GOODSIG", "BADSIG", "EXPSIG", "EXPKEYSIG", "REVKEYSIG":
+			info.Status = map[string]string{
+				"GOODSIG":   SignatureGood,
+				"BADSIG":    SignatureBad,
+				"EXPSIG":    SignatureExpired,
+				"EXPKEYSIG": SignatureExpired,
+				"REVKEYSIG": SignatureRevoked,
+			}[fields[0]]
+			if len(fields) > 1 {
+				info.KeyID = fields[1]
+			}
+			info.Signer = uid
+		case "ERRSIG":
+			if info.Status == "" {
+				info.Status = SignatureError
+			}
+			if len(fields) > 1 {
+				info.KeyID = fields[1]
+			}
+		case "NO_PUBKEY":
+			info.Status = SignatureUnknownKey
+			if len(fields) > 1 {
+				info.KeyID = fields[1]
+			}
+			info.Message = "the public key is not in your keyring"
+		case "VALIDSIG":
+			// The primary key fingerprint is the last field; the first is the
+			// fingerprint of the (sub)key that made the signature.
+			if len(fields) > 1 {
+				info.Fingerprint = fields[len(fields)-1]
+			}
+		case "TRUST_FULLY", "TRUST_ULTIMATE":
+			info.Trusted = true
+		}
+	}
+	if info.Status != SignatureGood {
+		info.Trusted = false
+	}
+	return info
+}
+
+var sshFingerprintRegex = regexp.MustCompile(`key (SHA256:[A-Za-z0-9+/=]+)`)
+
+// verifySSH verifies an SSH signature the way git does: against the allowed
+// signers file if there is one, otherwise only checking that the signature
+// is valid, which leaves the key unknown.
+func (v *signatureVerifier) verifySSH(signature string, payload []byte, signer object.Signature) SignatureInfo {
+	info := SignatureInfo{Format: "ssh"}
+
+	sigFile, err := writeTempSignature(signature)
+	if err != nil {
+		return SignatureInfo{Status: SignatureError, Format: "ssh", Message: err.Error()}
+	}
+	defer func() { _ = os.Remove(sigFile) }()
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := exec.Command(v.sshProgram, args...)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		err := cmd.Run()
+		return strings.TrimSpace(out.String()), err
+	}
+	verifyTime := "-Overify-time=" + signer.When.Format("20060102150405")
+
+	if v.allowedSigners != "" {
+		principals, err := run("-Y", "find-principals", "-f", v.allowedSigners, "-s", sigFile, verifyTime)
+		if err == nil && principals != "" {
+			principal := strings.Split(principals, "\n")[0]
+			out, err := run("-Y", "verify", "-f", v.allowedSigners, "-I", principal, "-n", "git", "-s", sigFile, verifyTime)
+			if m := sshFingerprintRegex.FindStringSubmatch(out); m != nil {
+				info.Fingerprint = m[1]
+			}
+			info.Signer = principal
+			if err != nil {
+				info.Status = SignatureBad
+				info.Message = out
+				return info
+			}
+			info.Status = SignatureGood
+			info.Trusted = true
+			return info
+		}
+	}
+
+	out, err := run("-Y", "check-novalidate", "-n", "git", "-s", sigFile)
+	if m := sshFingerprintRegex.FindStringSubmatch(out); m != nil {
+		info.Fingerprint = m[1]
+	}
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		info.Status = SignatureError
+		info.Message = fmt.Sprintf("%s is not installed", v.sshProgram)
+	case err != nil:
+		info.Status = SignatureBad
+		info.Message = out
+	default:
+		info.Status = SignatureUnknownKey
+		if v.allowedSigners == "" {
+			info.Message = "gpg.ssh.allowedSignersFile is not configured"
+		} else {
+			info.Message = "the key is not in the allowed signers file"
+		}
+	}
+	return info
+}
+
+func writeTempSignature(signature string) (string, error) {
+	f, err := os.CreateTemp("", ".git_vtag_tmp")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.WriteString(signature); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
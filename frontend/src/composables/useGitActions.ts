@@ -1,5 +1,6 @@
 import { ref, type Ref } from 'vue';
 import * as App from '../../wailsjs/go/backend/App';
+import { backend } from '../../wailsjs/go/models';
 import type { RepoTab } from '@/types/git.types';
 import { useAlerts } from './useAlerts';
 
@@ -128,9 +129,16 @@ export function useGitActions(
     }
   };
 
-  const createBranch = async (repoPath: string, name: string, checkout: boolean) => {
+  // An empty startPoint creates the branch at HEAD. Branches started from a
+  // remote-tracking branch track it as their upstream.
+  const createBranch = async (repoPath: string, name: string, checkout: boolean, startPoint = '') => {
     try {
-      await App.CreateBranch(repoPath, name, checkout);
+      await App.CreateBranchWithOptions(repoPath, backend.BranchOptions.createFrom({
+        name,
+        startPoint,
+        checkout,
+        track: ''
+      }));
       showSuccess(`Created branch ${name}`, 'New Branch');
     } catch (err) {
       console.error('Failed to create branch:', err);
@@ -138,6 +146,19 @@ export function useGitActions(
     }
   };
 
+  // An empty upstream unsets the branch's upstream.
+  const setUpstream = async (repoPath: string, branchName: string, upstream: string) => {
+    try {
+      await App.SetUpstream(repoPath, branchName, upstream);
+      showSuccess(upstream ? `${branchName} now tracks ${upstream}` : `Removed the upstream of ${branchName}`, 'Upstream');
+      return true;
+    } catch (err) {
+      console.error('Failed to set upstream:', err);
+      showError('Failed to set upstream: ' + err);
+      return false;
+    }
+  };
+
   const createTag = async (repoPath: string, name: string, message: string) => {
     try {
       await App.CreateTag(repoPath, name, message);
@@ -148,42 +169,72 @@ export function useGitActions(
     }
   };
 
-  const fetchRepo = async (repoPath: string) => {
+  const fetchRepo = async (repoPath: string, remote: string = '') => {
     try {
-      await App.Fetch(repoPath);
+      await App.Fetch(repoPath, remote);
       showSuccess('Fetch completed successfully', 'Fetch');
     } catch (err: any) {
       console.error('Failed to fetch:', err);
       if (err.toString().includes('SSH key not found')) {
         return 'ssh-key-missing';
+      } else if (err.toString().includes('operation cancelled')) {
+        return 'cancelled';
+      } else {
+        showError('Failed to fetch: ' + err);
+      }
+    }
+  };
+
+  const fetchAllRemotes = async (repoPath: string, prune: boolean = false) => {
+    try {
+      await App.FetchWithOptions(repoPath, backend.FetchOptions.createFrom({ remote: '', all: true, prune }));
+      showSuccess('Fetched all remotes successfully', 'Fetch');
+    } catch (err: any) {
+      console.error('Failed to fetch:', err);
+      if (err.toString().includes('SSH key not found')) {
+        return 'ssh-key-missing';
+      } else if (err.toString().includes('operation cancelled')) {
+        return 'cancelled';
       } else {
         showError('Failed to fetch: ' + err);
       }
     }
   };
 
-  const pullRepo = async (repoPath: string) => {
+  const pullRepo = async (repoPath: string, remote: string = '', mode: string = '', autostash: boolean = false) => {
     try {
-      await App.Pull(repoPath);
+      await App.PullWithOptions(repoPath, backend.PullOptions.createFrom({ remote, mode, autostash }));
       showSuccess('Pull completed successfully', 'Pull');
     } catch (err: any) {
       console.error('Failed to pull:', err);
       if (err.toString().includes('SSH key not found')) {
         return 'ssh-key-missing';
+      } else if (err.toString().includes('operation cancelled')) {
+        return 'cancelled';
       } else {
         showError('Failed to pull: ' + err);
       }
     }
   };
 
-  const pushRepo = async (repoPath: string) => {
+  const pushRepo = async (repoPath: string, noVerify: boolean = false, remote: string = '') => {
+    return pushWithOptions(repoPath, backend.PushOptions.createFrom({ remote, noVerify }));
+  };
+
+  const pushWithOptions = async (repoPath: string, options: backend.PushOptions) => {
     try {
-      await App.Push(repoPath);
+      await App.PushWithOptions(repoPath, options);
       showSuccess('Push completed successfully', 'Push');
+      return 'ok';
     } catch (err: any) {
       console.error('Failed to push:', err);
       if (err.toString().includes('SSH key not found')) {
         return 'ssh-key-missing';
+      } else if (err.toString().includes('operation cancelled')) {
+        return 'cancelled';
+      } else if (err.toString().includes('pre-push hook failed')) {
+        showError('Push rejected: ' + err);
+        return 'hook-failed';
       } else {
         showError('Failed to push: ' + err);
       }
@@ -201,9 +252,12 @@ export function useGitActions(
     addToRecent,
     checkoutBranch,
     createBranch,
+    setUpstream,
     createTag,
     fetchRepo,
+    fetchAllRemotes,
     pullRepo,
-    pushRepo
+    pushRepo,
+    pushWithOptions
   };
 }
@@ -25,3 +25,19 @@ export interface RepoTab {
     loading: boolean;
     error: string | null;
 }
+
+export interface CommitRequest {
+    subject: string;
+    description: string;
+    amend: boolean;
+    signOff: boolean;
+    sign: boolean;
+    noVerify: boolean;
+    allowEmpty: boolean;
+    fixupCommit: string;
+    fixupKind: '' | 'fixup' | 'squash' | 'amend';
+    authorName: string;
+    authorEmail: string;
+    authorDate: string;
+    paths: string[];
+}
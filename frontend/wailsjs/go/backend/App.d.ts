@@ -2,58 +2,196 @@
 // This file is automatically generated. DO NOT EDIT
 import {backend} from '../models';
 
+export function AddAllowedSigner(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function AddGitConfigValue(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function AddNote(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function AddRemote(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function CancelOperation(arg1:string):Promise<void>;
+
 export function Checkout(arg1:string,arg2:string,arg3:boolean):Promise<void>;
 
+export function Clone(arg1:backend.CloneOptions):Promise<string>;
+
 export function Commit(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<void>;
 
+export function CommitWithOptions(arg1:string,arg2:backend.CommitOptions):Promise<void>;
+
+export function CountCommits(arg1:string,arg2:string):Promise<number>;
+
 export function CreateBranch(arg1:string,arg2:string,arg3:boolean):Promise<void>;
 
+export function CreateBranchWithOptions(arg1:string,arg2:backend.BranchOptions):Promise<void>;
+
 export function CreateTag(arg1:string,arg2:string,arg3:string):Promise<void>;
 
-export function DeleteBranch(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+export function DefaultCloneDirectory(arg1:string,arg2:boolean):Promise<string>;
 
-export function Fetch(arg1:string):Promise<void>;
+export function DeleteBranch(arg1:string,arg2:string,arg3:boolean,arg4:string):Promise<void>;
+
+export function DeleteBranches(arg1:string,arg2:Array<string>,arg3:boolean):Promise<void>;
+
+export function DeleteHook(arg1:string,arg2:string):Promise<void>;
+
+export function DeleteStoredCredential(arg1:string,arg2:string):Promise<void>;
+
+export function EditNote(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function Fetch(arg1:string,arg2:string):Promise<void>;
+
+export function FetchAll(arg1:string):Promise<void>;
+
+export function FetchWithOptions(arg1:string,arg2:backend.FetchOptions):Promise<void>;
 
 export function GenerateSshKey():Promise<backend.SshKeyInfo>;
 
+export function GetBranchTracking(arg1:string):Promise<Array<backend.BranchTracking>>;
+
 export function GetBranches(arg1:string):Promise<Array<string>>;
 
 export function GetCommitChanges(arg1:string,arg2:string):Promise<Array<backend.CommitFileChange>>;
 
 export function GetCommitFileDiff(arg1:string,arg2:string,arg3:string):Promise<string>;
 
+export function GetCommitGraph(arg1:string,arg2:backend.GraphOptions):Promise<backend.CommitGraph>;
+
 export function GetCommitHistory(arg1:string,arg2:number):Promise<Array<backend.GitCommit>>;
 
+export function GetCommitMessageSettings(arg1:string):Promise<backend.CommitMessageSettings>;
+
+export function GetCommitTemplate(arg1:string):Promise<string>;
+
+export function GetFileAtRevision(arg1:string,arg2:string,arg3:string):Promise<backend.FileAtRevision>;
+
 export function GetFileDiff(arg1:string,arg2:string,arg3:boolean):Promise<string>;
 
+export function GetGitIdentity(arg1:string):Promise<backend.GitIdentity>;
+
 export function GetGitStatus(arg1:string):Promise<Array<backend.GitStatusFile>>;
 
 export function GetHomeDir():Promise<string>;
 
+export function GetHookContent(arg1:string,arg2:string):Promise<string>;
+
+export function GetHookTemplates(arg1:string):Promise<Array<backend.HookTemplate>>;
+
+export function GetIdentityProfiles():Promise<backend.ProfileSettings>;
+
+export function GetNotes(arg1:string,arg2:string):Promise<Array<backend.GitNote>>;
+
+export function GetNotesRefs(arg1:string):Promise<Array<string>>;
+
+export function GetOperations():Promise<Array<backend.Operation>>;
+
+export function GetProtectedBranches(arg1:string):Promise<Array<string>>;
+
+export function GetRepoProfile(arg1:string):Promise<backend.IdentityProfile>;
+
 export function GetRepoReadme(arg1:string):Promise<string>;
 
 export function GetRepoStats(arg1:string):Promise<backend.RepoStats>;
 
+export function GetSigningConfig(arg1:string):Promise<backend.SigningConfig>;
+
 export function GetSshKeyInfo():Promise<backend.SshKeyInfo>;
 
+export function GetStaleBranches(arg1:string):Promise<backend.StaleBranchReport>;
+
+export function GetStoredCredentials():Promise<Array<backend.StoredCredential>>;
+
+export function GetTags(arg1:string):Promise<Array<backend.GitTag>>;
+
+export function GetTreeAtRevision(arg1:string,arg2:string,arg3:string):Promise<Array<backend.TreeEntry>>;
+
 export function GitInit(arg1:string):Promise<void>;
 
+export function InstallHookTemplate(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+
+export function IsAncestor(arg1:string,arg2:string,arg3:string):Promise<boolean>;
+
 export function IsGitRepo(arg1:string):Promise<boolean>;
 
+export function ListGitConfig(arg1:string):Promise<Array<backend.GitConfigEntry>>;
+
+export function ListHooks(arg1:string):Promise<backend.HooksInfo>;
+
+export function ListRemotes(arg1:string):Promise<Array<backend.GitRemote>>;
+
+export function ListSshKeys():Promise<Array<backend.SshKeyInfo>>;
+
 export function OpenInBrowser(arg1:string):Promise<void>;
 
 export function OpenInFileManager(arg1:string):Promise<void>;
 
-export function Pull(arg1:string):Promise<void>;
+export function PreflightPush(arg1:string,arg2:backend.PushOptions):Promise<Array<backend.PushPreview>>;
+
+export function ProvideCredential(arg1:string,arg2:backend.CredentialResponse):Promise<void>;
+
+export function Pull(arg1:string,arg2:string):Promise<void>;
+
+export function PullWithOptions(arg1:string,arg2:backend.PullOptions):Promise<void>;
+
+export function Push(arg1:string,arg2:string):Promise<void>;
+
+export function PushWithOptions(arg1:string,arg2:backend.PushOptions):Promise<void>;
+
+export function RemoveNote(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function RemoveRemote(arg1:string,arg2:string):Promise<void>;
+
+export function RenameGitConfigKey(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function RenameRemote(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function ResetStoredCredentials():Promise<void>;
+
+export function SaveCommitMessageSettings(arg1:string,arg2:backend.CommitMessageSettings):Promise<backend.CommitMessageSettings>;
 
-export function Push(arg1:string):Promise<void>;
+export function SaveFileAtRevision(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function SaveHook(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function SaveIdentityProfiles(arg1:backend.ProfileSettings):Promise<backend.ProfileSettings>;
 
 export function SelectDirectory(arg1:string):Promise<string>;
 
+export function SelectSaveFile(arg1:string,arg2:string):Promise<string>;
+
+export function SetGitConfig(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function SetGitConfigValues(arg1:string,arg2:string,arg3:string,arg4:Array<string>):Promise<void>;
+
+export function SetHookEnabled(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+
+export function SetHooksPath(arg1:string,arg2:string):Promise<void>;
+
+export function SetProtectedBranches(arg1:string,arg2:Array<string>):Promise<Array<string>>;
+
+export function SetUpstream(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function StageAll(arg1:string):Promise<void>;
 
 export function StageFile(arg1:string,arg2:string):Promise<void>;
 
+export function TestRemote(arg1:string,arg2:string):Promise<backend.RemoteTestResult>;
+
+export function TestRemoteURL(arg1:string,arg2:string):Promise<backend.RemoteTestResult>;
+
+export function UnsetGitConfig(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function UnstageAll(arg1:string):Promise<void>;
 
 export function UnstageFile(arg1:string,arg2:string):Promise<void>;
+
+export function UpdateRemote(arg1:string,arg2:backend.GitRemote):Promise<void>;
+
+export function UpdateRemoteHead(arg1:string,arg2:string):Promise<string>;
+
+export function ValidateCommitMessage(arg1:string,arg2:string):Promise<Array<backend.CommitMessageViolation>>;
+
+export function ValidateGitConfig(arg1:string,arg2:string):Promise<void>;
+
+export function VerifyCommitSignature(arg1:string,arg2:string):Promise<backend.SignatureInfo>;
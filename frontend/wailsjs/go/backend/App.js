@@ -2,34 +2,102 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAllowedSigner(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['AddAllowedSigner'](arg1, arg2, arg3);
+}
+
+export function AddGitConfigValue(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['AddGitConfigValue'](arg1, arg2, arg3, arg4);
+}
+
+export function AddNote(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['AddNote'](arg1, arg2, arg3, arg4);
+}
+
+export function AddRemote(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['AddRemote'](arg1, arg2, arg3);
+}
+
+export function CancelOperation(arg1) {
+  return window['go']['backend']['App']['CancelOperation'](arg1);
+}
+
 export function Checkout(arg1, arg2, arg3) {
   return window['go']['backend']['App']['Checkout'](arg1, arg2, arg3);
 }
 
+export function Clone(arg1) {
+  return window['go']['backend']['App']['Clone'](arg1);
+}
+
 export function Commit(arg1, arg2, arg3, arg4) {
   return window['go']['backend']['App']['Commit'](arg1, arg2, arg3, arg4);
 }
 
+export function CommitWithOptions(arg1, arg2) {
+  return window['go']['backend']['App']['CommitWithOptions'](arg1, arg2);
+}
+
+export function CountCommits(arg1, arg2) {
+  return window['go']['backend']['App']['CountCommits'](arg1, arg2);
+}
+
 export function CreateBranch(arg1, arg2, arg3) {
   return window['go']['backend']['App']['CreateBranch'](arg1, arg2, arg3);
 }
 
+export function CreateBranchWithOptions(arg1, arg2) {
+  return window['go']['backend']['App']['CreateBranchWithOptions'](arg1, arg2);
+}
+
 export function CreateTag(arg1, arg2, arg3) {
   return window['go']['backend']['App']['CreateTag'](arg1, arg2, arg3);
 }
 
-export function DeleteBranch(arg1, arg2, arg3) {
-  return window['go']['backend']['App']['DeleteBranch'](arg1, arg2, arg3);
+export function DefaultCloneDirectory(arg1, arg2) {
+  return window['go']['backend']['App']['DefaultCloneDirectory'](arg1, arg2);
 }
 
-export function Fetch(arg1) {
-  return window['go']['backend']['App']['Fetch'](arg1);
+export function DeleteBranch(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['DeleteBranch'](arg1, arg2, arg3, arg4);
+}
+
+export function DeleteBranches(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['DeleteBranches'](arg1, arg2, arg3);
+}
+
+export function DeleteHook(arg1, arg2) {
+  return window['go']['backend']['App']['DeleteHook'](arg1, arg2);
+}
+
+export function DeleteStoredCredential(arg1, arg2) {
+  return window['go']['backend']['App']['DeleteStoredCredential'](arg1, arg2);
+}
+
+export function EditNote(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['EditNote'](arg1, arg2, arg3, arg4);
+}
+
+export function Fetch(arg1, arg2) {
+  return window['go']['backend']['App']['Fetch'](arg1, arg2);
+}
+
+export function FetchAll(arg1) {
+  return window['go']['backend']['App']['FetchAll'](arg1);
+}
+
+export function FetchWithOptions(arg1, arg2) {
+  return window['go']['backend']['App']['FetchWithOptions'](arg1, arg2);
 }
 
 export function GenerateSshKey() {
   return window['go']['backend']['App']['GenerateSshKey']();
 }
 
+export function GetBranchTracking(arg1) {
+  return window['go']['backend']['App']['GetBranchTracking'](arg1);
+}
+
 export function GetBranches(arg1) {
   return window['go']['backend']['App']['GetBranches'](arg1);
 }
@@ -42,14 +110,34 @@ export function GetCommitFileDiff(arg1, arg2, arg3) {
   return window['go']['backend']['App']['GetCommitFileDiff'](arg1, arg2, arg3);
 }
 
+export function GetCommitGraph(arg1, arg2) {
+  return window['go']['backend']['App']['GetCommitGraph'](arg1, arg2);
+}
+
 export function GetCommitHistory(arg1, arg2) {
   return window['go']['backend']['App']['GetCommitHistory'](arg1, arg2);
 }
 
+export function GetCommitMessageSettings(arg1) {
+  return window['go']['backend']['App']['GetCommitMessageSettings'](arg1);
+}
+
+export function GetCommitTemplate(arg1) {
+  return window['go']['backend']['App']['GetCommitTemplate'](arg1);
+}
+
+export function GetFileAtRevision(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['GetFileAtRevision'](arg1, arg2, arg3);
+}
+
 export function GetFileDiff(arg1, arg2, arg3) {
   return window['go']['backend']['App']['GetFileDiff'](arg1, arg2, arg3);
 }
 
+export function GetGitIdentity(arg1) {
+  return window['go']['backend']['App']['GetGitIdentity'](arg1);
+}
+
 export function GetGitStatus(arg1) {
   return window['go']['backend']['App']['GetGitStatus'](arg1);
 }
@@ -58,6 +146,38 @@ export function GetHomeDir() {
   return window['go']['backend']['App']['GetHomeDir']();
 }
 
+export function GetHookContent(arg1, arg2) {
+  return window['go']['backend']['App']['GetHookContent'](arg1, arg2);
+}
+
+export function GetHookTemplates(arg1) {
+  return window['go']['backend']['App']['GetHookTemplates'](arg1);
+}
+
+export function GetIdentityProfiles() {
+  return window['go']['backend']['App']['GetIdentityProfiles']();
+}
+
+export function GetNotes(arg1, arg2) {
+  return window['go']['backend']['App']['GetNotes'](arg1, arg2);
+}
+
+export function GetNotesRefs(arg1) {
+  return window['go']['backend']['App']['GetNotesRefs'](arg1);
+}
+
+export function GetOperations() {
+  return window['go']['backend']['App']['GetOperations']();
+}
+
+export function GetProtectedBranches(arg1) {
+  return window['go']['backend']['App']['GetProtectedBranches'](arg1);
+}
+
+export function GetRepoProfile(arg1) {
+  return window['go']['backend']['App']['GetRepoProfile'](arg1);
+}
+
 export function GetRepoReadme(arg1) {
   return window['go']['backend']['App']['GetRepoReadme'](arg1);
 }
@@ -66,18 +186,62 @@ export function GetRepoStats(arg1) {
   return window['go']['backend']['App']['GetRepoStats'](arg1);
 }
 
+export function GetSigningConfig(arg1) {
+  return window['go']['backend']['App']['GetSigningConfig'](arg1);
+}
+
 export function GetSshKeyInfo() {
   return window['go']['backend']['App']['GetSshKeyInfo']();
 }
 
+export function GetStaleBranches(arg1) {
+  return window['go']['backend']['App']['GetStaleBranches'](arg1);
+}
+
+export function GetStoredCredentials() {
+  return window['go']['backend']['App']['GetStoredCredentials']();
+}
+
+export function GetTags(arg1) {
+  return window['go']['backend']['App']['GetTags'](arg1);
+}
+
+export function GetTreeAtRevision(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['GetTreeAtRevision'](arg1, arg2, arg3);
+}
+
 export function GitInit(arg1) {
   return window['go']['backend']['App']['GitInit'](arg1);
 }
 
+export function InstallHookTemplate(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['InstallHookTemplate'](arg1, arg2, arg3);
+}
+
+export function IsAncestor(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['IsAncestor'](arg1, arg2, arg3);
+}
+
 export function IsGitRepo(arg1) {
   return window['go']['backend']['App']['IsGitRepo'](arg1);
 }
 
+export function ListGitConfig(arg1) {
+  return window['go']['backend']['App']['ListGitConfig'](arg1);
+}
+
+export function ListHooks(arg1) {
+  return window['go']['backend']['App']['ListHooks'](arg1);
+}
+
+export function ListRemotes(arg1) {
+  return window['go']['backend']['App']['ListRemotes'](arg1);
+}
+
+export function ListSshKeys() {
+  return window['go']['backend']['App']['ListSshKeys']();
+}
+
 export function OpenInBrowser(arg1) {
   return window['go']['backend']['App']['OpenInBrowser'](arg1);
 }
@@ -86,18 +250,98 @@ export function OpenInFileManager(arg1) {
   return window['go']['backend']['App']['OpenInFileManager'](arg1);
 }
 
-export function Pull(arg1) {
-  return window['go']['backend']['App']['Pull'](arg1);
+export function PreflightPush(arg1, arg2) {
+  return window['go']['backend']['App']['PreflightPush'](arg1, arg2);
+}
+
+export function ProvideCredential(arg1, arg2) {
+  return window['go']['backend']['App']['ProvideCredential'](arg1, arg2);
+}
+
+export function Pull(arg1, arg2) {
+  return window['go']['backend']['App']['Pull'](arg1, arg2);
+}
+
+export function PullWithOptions(arg1, arg2) {
+  return window['go']['backend']['App']['PullWithOptions'](arg1, arg2);
+}
+
+export function Push(arg1, arg2) {
+  return window['go']['backend']['App']['Push'](arg1, arg2);
+}
+
+export function PushWithOptions(arg1, arg2) {
+  return window['go']['backend']['App']['PushWithOptions'](arg1, arg2);
+}
+
+export function RemoveNote(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['RemoveNote'](arg1, arg2, arg3);
+}
+
+export function RemoveRemote(arg1, arg2) {
+  return window['go']['backend']['App']['RemoveRemote'](arg1, arg2);
+}
+
+export function RenameGitConfigKey(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['RenameGitConfigKey'](arg1, arg2, arg3, arg4);
+}
+
+export function RenameRemote(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['RenameRemote'](arg1, arg2, arg3);
+}
+
+export function ResetStoredCredentials() {
+  return window['go']['backend']['App']['ResetStoredCredentials']();
+}
+
+export function SaveCommitMessageSettings(arg1, arg2) {
+  return window['go']['backend']['App']['SaveCommitMessageSettings'](arg1, arg2);
 }
 
-export function Push(arg1) {
-  return window['go']['backend']['App']['Push'](arg1);
+export function SaveFileAtRevision(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['SaveFileAtRevision'](arg1, arg2, arg3, arg4);
+}
+
+export function SaveHook(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['SaveHook'](arg1, arg2, arg3);
+}
+
+export function SaveIdentityProfiles(arg1) {
+  return window['go']['backend']['App']['SaveIdentityProfiles'](arg1);
 }
 
 export function SelectDirectory(arg1) {
   return window['go']['backend']['App']['SelectDirectory'](arg1);
 }
 
+export function SelectSaveFile(arg1, arg2) {
+  return window['go']['backend']['App']['SelectSaveFile'](arg1, arg2);
+}
+
+export function SetGitConfig(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['SetGitConfig'](arg1, arg2, arg3, arg4);
+}
+
+export function SetGitConfigValues(arg1, arg2, arg3, arg4) {
+  return window['go']['backend']['App']['SetGitConfigValues'](arg1, arg2, arg3, arg4);
+}
+
+export function SetHookEnabled(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['SetHookEnabled'](arg1, arg2, arg3);
+}
+
+export function SetHooksPath(arg1, arg2) {
+  return window['go']['backend']['App']['SetHooksPath'](arg1, arg2);
+}
+
+export function SetProtectedBranches(arg1, arg2) {
+  return window['go']['backend']['App']['SetProtectedBranches'](arg1, arg2);
+}
+
+export function SetUpstream(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['SetUpstream'](arg1, arg2, arg3);
+}
+
 export function StageAll(arg1) {
   return window['go']['backend']['App']['StageAll'](arg1);
 }
@@ -106,6 +350,18 @@ export function StageFile(arg1, arg2) {
   return window['go']['backend']['App']['StageFile'](arg1, arg2);
 }
 
+export function TestRemote(arg1, arg2) {
+  return window['go']['backend']['App']['TestRemote'](arg1, arg2);
+}
+
+export function TestRemoteURL(arg1, arg2) {
+  return window['go']['backend']['App']['TestRemoteURL'](arg1, arg2);
+}
+
+export function UnsetGitConfig(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['UnsetGitConfig'](arg1, arg2, arg3);
+}
+
 export function UnstageAll(arg1) {
   return window['go']['backend']['App']['UnstageAll'](arg1);
 }
@@ -113,3 +369,23 @@ export function UnstageAll(arg1) {
 export function UnstageFile(arg1, arg2) {
   return window['go']['backend']['App']['UnstageFile'](arg1, arg2);
 }
+
+export function UpdateRemote(arg1, arg2) {
+  return window['go']['backend']['App']['UpdateRemote'](arg1, arg2);
+}
+
+export function UpdateRemoteHead(arg1, arg2) {
+  return window['go']['backend']['App']['UpdateRemoteHead'](arg1, arg2);
+}
+
+export function ValidateCommitMessage(arg1, arg2) {
+  return window['go']['backend']['App']['ValidateCommitMessage'](arg1, arg2);
+}
+
+export function ValidateGitConfig(arg1, arg2) {
+  return window['go']['backend']['App']['ValidateGitConfig'](arg1, arg2);
+}
+
+export function VerifyCommitSignature(arg1, arg2) {
+  return window['go']['backend']['App']['VerifyCommitSignature'](arg1, arg2);
+}
@@ -1,44 +1,617 @@
 export namespace backend {
 	
+	export class BranchOptions {
+	    name: string;
+	    startPoint: string;
+	    checkout: boolean;
+	    track: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BranchOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.startPoint = source["startPoint"];
+	        this.checkout = source["checkout"];
+	        this.track = source["track"];
+	    }
+	}
+	export class BranchTracking {
+	    branch: string;
+	    upstream: string;
+	    ahead: number;
+	    behind: number;
+	    gone: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BranchTracking(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.branch = source["branch"];
+	        this.upstream = source["upstream"];
+	        this.ahead = source["ahead"];
+	        this.behind = source["behind"];
+	        this.gone = source["gone"];
+	    }
+	}
+	export class CloneOptions {
+	    url: string;
+	    directory: string;
+	    branch: string;
+	    depth: number;
+	    singleBranch: boolean;
+	    recurseSubmodules: boolean;
+	    bare: boolean;
+	    mirror: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CloneOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.directory = source["directory"];
+	        this.branch = source["branch"];
+	        this.depth = source["depth"];
+	        this.singleBranch = source["singleBranch"];
+	        this.recurseSubmodules = source["recurseSubmodules"];
+	        this.bare = source["bare"];
+	        this.mirror = source["mirror"];
+	    }
+	}
 	export class CommitFileChange {
 	    path: string;
 	    status: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new CommitFileChange(source);
+	        return new CommitFileChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.status = source["status"];
+	    }
+	}
+	export class GraphEdge {
+	    fromLane: number;
+	    toLane: number;
+	    color: number;
+	    kind: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GraphEdge(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fromLane = source["fromLane"];
+	        this.toLane = source["toLane"];
+	        this.color = source["color"];
+	        this.kind = source["kind"];
+	    }
+	}
+	export class SignatureInfo {
+	    status: string;
+	    format: string;
+	    signer: string;
+	    keyId: string;
+	    fingerprint: string;
+	    trusted: boolean;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SignatureInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.format = source["format"];
+	        this.signer = source["signer"];
+	        this.keyId = source["keyId"];
+	        this.fingerprint = source["fingerprint"];
+	        this.trusted = source["trusted"];
+	        this.message = source["message"];
+	    }
+	}
+	export class CommitTrailer {
+	    key: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitTrailer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	    }
+	}
+	export class GitNote {
+	    ref: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitNote(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ref = source["ref"];
+	        this.message = source["message"];
+	    }
+	}
+	export class GitCommit {
+	    hash: string;
+	    authorName: string;
+	    authorEmail: string;
+	    // Go type: time
+	    date: any;
+	    subject: string;
+	    body: string;
+	    parentHashes: string[];
+	    refs: string[];
+	    notes: GitNote[];
+	    trailers: CommitTrailer[];
+	    signature: SignatureInfo;
+	    onProtectedBranch: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitCommit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.authorName = source["authorName"];
+	        this.authorEmail = source["authorEmail"];
+	        this.date = this.convertValues(source["date"], null);
+	        this.subject = source["subject"];
+	        this.body = source["body"];
+	        this.parentHashes = source["parentHashes"];
+	        this.refs = source["refs"];
+	        this.notes = this.convertValues(source["notes"], GitNote);
+	        this.trailers = this.convertValues(source["trailers"], CommitTrailer);
+	        this.signature = this.convertValues(source["signature"], SignatureInfo);
+	        this.onProtectedBranch = source["onProtectedBranch"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GraphRow {
+	    commit: GitCommit;
+	    lane: number;
+	    color: number;
+	    laneCount: number;
+	    edges: GraphEdge[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GraphRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.commit = this.convertValues(source["commit"], GitCommit);
+	        this.lane = source["lane"];
+	        this.color = source["color"];
+	        this.laneCount = source["laneCount"];
+	        this.edges = this.convertValues(source["edges"], GraphEdge);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CommitGraph {
+	    rows: GraphRow[];
+	    total: number;
+	    hasMore: boolean;
+	    laneCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitGraph(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rows = this.convertValues(source["rows"], GraphRow);
+	        this.total = source["total"];
+	        this.hasMore = source["hasMore"];
+	        this.laneCount = source["laneCount"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CommitMessageRules {
+	    conventionalCommits: boolean;
+	    conventionalTypes: string[];
+	    maxSubjectLength: number;
+	    ticketPattern: string;
+	    bodyLineWrap: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitMessageRules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.conventionalCommits = source["conventionalCommits"];
+	        this.conventionalTypes = source["conventionalTypes"];
+	        this.maxSubjectLength = source["maxSubjectLength"];
+	        this.ticketPattern = source["ticketPattern"];
+	        this.bodyLineWrap = source["bodyLineWrap"];
+	    }
+	}
+	export class CommitMessageTemplate {
+	    id: string;
+	    name: string;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitMessageTemplate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.content = source["content"];
+	    }
+	}
+	export class CommitMessageSettings {
+	    templates: CommitMessageTemplate[];
+	    rules: CommitMessageRules;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitMessageSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.templates = this.convertValues(source["templates"], CommitMessageTemplate);
+	        this.rules = this.convertValues(source["rules"], CommitMessageRules);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class CommitMessageViolation {
+	    rule: string;
+	    line: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitMessageViolation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rule = source["rule"];
+	        this.line = source["line"];
+	        this.message = source["message"];
+	    }
+	}
+	export class CommitOptions {
+	    subject: string;
+	    body: string;
+	    amend: boolean;
+	    trailers: CommitTrailer[];
+	    signOff: boolean;
+	    sign: boolean;
+	    noVerify: boolean;
+	    allowEmpty: boolean;
+	    fixupCommit: string;
+	    fixupKind: string;
+	    authorName: string;
+	    authorEmail: string;
+	    authorDate: string;
+	    paths: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subject = source["subject"];
+	        this.body = source["body"];
+	        this.amend = source["amend"];
+	        this.trailers = this.convertValues(source["trailers"], CommitTrailer);
+	        this.signOff = source["signOff"];
+	        this.sign = source["sign"];
+	        this.noVerify = source["noVerify"];
+	        this.allowEmpty = source["allowEmpty"];
+	        this.fixupCommit = source["fixupCommit"];
+	        this.fixupKind = source["fixupKind"];
+	        this.authorName = source["authorName"];
+	        this.authorEmail = source["authorEmail"];
+	        this.authorDate = source["authorDate"];
+	        this.paths = source["paths"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class CredentialResponse {
+	    username: string;
+	    password: string;
+	    remember: boolean;
+	    cancel: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CredentialResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.remember = source["remember"];
+	        this.cancel = source["cancel"];
+	    }
+	}
+	export class FetchOptions {
+	    remote: string;
+	    all: boolean;
+	    prune: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FetchOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.remote = source["remote"];
+	        this.all = source["all"];
+	        this.prune = source["prune"];
+	    }
+	}
+	export class FileAtRevision {
+	    path: string;
+	    size: number;
+	    isBinary: boolean;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileAtRevision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.isBinary = source["isBinary"];
+	        this.content = source["content"];
+	    }
+	}
+	
+	export class GitConfigEntry {
+	    key: string;
+	    value: string;
+	    scope: string;
+	    file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitConfigEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.scope = source["scope"];
+	        this.file = source["file"];
+	    }
+	}
+	export class GitHook {
+	    name: string;
+	    path: string;
+	    installed: boolean;
+	    enabled: boolean;
+	    executable: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitHook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.installed = source["installed"];
+	        this.enabled = source["enabled"];
+	        this.executable = source["executable"];
+	    }
+	}
+	export class GitIdentity {
+	    name: string;
+	    email: string;
+	    nameOrigin: string;
+	    emailOrigin: string;
+	    signingKey: string;
+	    profile: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitIdentity(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.email = source["email"];
+	        this.nameOrigin = source["nameOrigin"];
+	        this.emailOrigin = source["emailOrigin"];
+	        this.signingKey = source["signingKey"];
+	        this.profile = source["profile"];
+	    }
+	}
+	
+	export class GitRemote {
+	    name: string;
+	    urls: string[];
+	    pushUrls: string[];
+	    fetchRefSpecs: string[];
+	    pushRefSpecs: string[];
+	    headBranch: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitRemote(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.urls = source["urls"];
+	        this.pushUrls = source["pushUrls"];
+	        this.fetchRefSpecs = source["fetchRefSpecs"];
+	        this.pushRefSpecs = source["pushRefSpecs"];
+	        this.headBranch = source["headBranch"];
+	    }
+	}
+	export class GitRemoteBranches {
+	    name: string;
+	    url: string;
+	    branches: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GitRemoteBranches(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.url = source["url"];
+	        this.branches = source["branches"];
+	    }
+	}
+	export class GitStatusFile {
+	    path: string;
+	    status: string;
+	    is_staged: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitStatusFile(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.path = source["path"];
 	        this.status = source["status"];
+	        this.is_staged = source["is_staged"];
 	    }
 	}
-	export class GitCommit {
+	export class GitTag {
+	    name: string;
 	    hash: string;
-	    authorName: string;
-	    authorEmail: string;
+	    commitHash: string;
+	    annotated: boolean;
+	    message: string;
+	    taggerName: string;
+	    taggerEmail: string;
 	    // Go type: time
 	    date: any;
-	    subject: string;
-	    body: string;
-	    parentHashes: string[];
-	    refs: string[];
+	    signature: SignatureInfo;
 	
 	    static createFrom(source: any = {}) {
-	        return new GitCommit(source);
+	        return new GitTag(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
 	        this.hash = source["hash"];
-	        this.authorName = source["authorName"];
-	        this.authorEmail = source["authorEmail"];
+	        this.commitHash = source["commitHash"];
+	        this.annotated = source["annotated"];
+	        this.message = source["message"];
+	        this.taggerName = source["taggerName"];
+	        this.taggerEmail = source["taggerEmail"];
 	        this.date = this.convertValues(source["date"], null);
-	        this.subject = source["subject"];
-	        this.body = source["body"];
-	        this.parentHashes = source["parentHashes"];
-	        this.refs = source["refs"];
+	        this.signature = this.convertValues(source["signature"], SignatureInfo);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -59,34 +632,289 @@ export namespace backend {
 		    return a;
 		}
 	}
-	export class GitRemoteBranches {
+	
+	export class GraphOptions {
+	    skip: number;
+	    count: number;
+	    firstParent: boolean;
+	    simplifyByDecoration: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GraphOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.skip = source["skip"];
+	        this.count = source["count"];
+	        this.firstParent = source["firstParent"];
+	        this.simplifyByDecoration = source["simplifyByDecoration"];
+	    }
+	}
+	
+	export class HookTemplate {
+	    id: string;
+	    hook: string;
+	    title: string;
+	    description: string;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HookTemplate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.hook = source["hook"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.content = source["content"];
+	    }
+	}
+	export class HooksInfo {
+	    dir: string;
+	    hooksPath: string;
+	    hooks: GitHook[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HooksInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.hooksPath = source["hooksPath"];
+	        this.hooks = this.convertValues(source["hooks"], GitHook);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IdentityProfile {
+	    id: string;
+	    label: string;
 	    name: string;
-	    branches: string[];
+	    email: string;
+	    signingKey: string;
+	    sshKeyPath: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GitRemoteBranches(source);
+	        return new IdentityProfile(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
 	        this.name = source["name"];
-	        this.branches = source["branches"];
+	        this.email = source["email"];
+	        this.signingKey = source["signingKey"];
+	        this.sshKeyPath = source["sshKeyPath"];
 	    }
 	}
-	export class GitStatusFile {
+	export class Operation {
+	    id: string;
+	    kind: string;
+	    repoPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Operation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.repoPath = source["repoPath"];
+	    }
+	}
+	export class ProfileRule {
+	    profileId: string;
 	    path: string;
-	    status: string;
-	    is_staged: boolean;
+	    remote: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GitStatusFile(source);
+	        return new ProfileRule(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profileId = source["profileId"];
 	        this.path = source["path"];
+	        this.remote = source["remote"];
+	    }
+	}
+	export class ProfileSettings {
+	    profiles: IdentityProfile[];
+	    rules: ProfileRule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profiles = this.convertValues(source["profiles"], IdentityProfile);
+	        this.rules = this.convertValues(source["rules"], ProfileRule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PullOptions {
+	    remote: string;
+	    mode: string;
+	    autostash: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PullOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.remote = source["remote"];
+	        this.mode = source["mode"];
+	        this.autostash = source["autostash"];
+	    }
+	}
+	export class PushOptions {
+	    remote: string;
+	    noVerify: boolean;
+	    branch: string;
+	    remoteBranch: string;
+	    setUpstream: boolean;
+	    force: boolean;
+	    forceWithLease: boolean;
+	    expectedHash: string;
+	    tags: string[];
+	    allTags: boolean;
+	    atomic: boolean;
+	    options: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PushOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.remote = source["remote"];
+	        this.noVerify = source["noVerify"];
+	        this.branch = source["branch"];
+	        this.remoteBranch = source["remoteBranch"];
+	        this.setUpstream = source["setUpstream"];
+	        this.force = source["force"];
+	        this.forceWithLease = source["forceWithLease"];
+	        this.expectedHash = source["expectedHash"];
+	        this.tags = source["tags"];
+	        this.allTags = source["allTags"];
+	        this.atomic = source["atomic"];
+	        this.options = source["options"];
+	    }
+	}
+	export class PushRefUpdate {
+	    local: string;
+	    remote: string;
+	    oldHash: string;
+	    newHash: string;
+	    status: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PushRefUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.local = source["local"];
+	        this.remote = source["remote"];
+	        this.oldHash = source["oldHash"];
+	        this.newHash = source["newHash"];
 	        this.status = source["status"];
-	        this.is_staged = source["is_staged"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class PushPreview {
+	    remote: string;
+	    url: string;
+	    updates: PushRefUpdate[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PushPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.remote = source["remote"];
+	        this.url = source["url"];
+	        this.updates = this.convertValues(source["updates"], PushRefUpdate);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class RemoteTestResult {
+	    url: string;
+	    headBranch: string;
+	    branches: string[];
+	    empty: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteTestResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.headBranch = source["headBranch"];
+	        this.branches = source["branches"];
+	        this.empty = source["empty"];
 	    }
 	}
 	export class RepoStats {
@@ -105,6 +933,7 @@ export namespace backend {
 	    tags: string[];
 	    stashes: string[];
 	    currentBranch: string;
+	    tracking: BranchTracking[];
 	
 	    static createFrom(source: any = {}) {
 	        return new RepoStats(source);
@@ -125,6 +954,7 @@ export namespace backend {
 	        this.tags = source["tags"];
 	        this.stashes = source["stashes"];
 	        this.currentBranch = source["currentBranch"];
+	        this.tracking = this.convertValues(source["tracking"], BranchTracking);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -145,6 +975,31 @@ export namespace backend {
 		    return a;
 		}
 	}
+	
+	export class SigningConfig {
+	    format: string;
+	    key: string;
+	    defaultKey: boolean;
+	    program: string;
+	    signCommit: boolean;
+	    signTag: boolean;
+	    allowedSignersFile: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SigningConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.key = source["key"];
+	        this.defaultKey = source["defaultKey"];
+	        this.program = source["program"];
+	        this.signCommit = source["signCommit"];
+	        this.signTag = source["signTag"];
+	        this.allowedSignersFile = source["allowedSignersFile"];
+	    }
+	}
 	export class SshKeyInfo {
 	    public_key: string;
 	    has_key: boolean;
@@ -161,6 +1016,100 @@ export namespace backend {
 	        this.path = source["path"];
 	    }
 	}
+	export class StaleBranch {
+	    name: string;
+	    upstream: string;
+	    gone: boolean;
+	    merged: boolean;
+	    hash: string;
+	    subject: string;
+	    lastCommit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StaleBranch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.upstream = source["upstream"];
+	        this.gone = source["gone"];
+	        this.merged = source["merged"];
+	        this.hash = source["hash"];
+	        this.subject = source["subject"];
+	        this.lastCommit = source["lastCommit"];
+	    }
+	}
+	export class StaleBranchReport {
+	    defaultBranch: string;
+	    branches: StaleBranch[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StaleBranchReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.defaultBranch = source["defaultBranch"];
+	        this.branches = this.convertValues(source["branches"], StaleBranch);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StoredCredential {
+	    url: string;
+	    username: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StoredCredential(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.username = source["username"];
+	    }
+	}
+	export class TreeEntry {
+	    name: string;
+	    path: string;
+	    mode: string;
+	    type: string;
+	    size: number;
+	    hash: string;
+	    submoduleUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TreeEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.mode = source["mode"];
+	        this.type = source["type"];
+	        this.size = source["size"];
+	        this.hash = source["hash"];
+	        this.submoduleUrl = source["submoduleUrl"];
+	    }
+	}
 
 }
 